@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
 	"cyberhunt/internal/database"
 	"cyberhunt/internal/handlers"
 	"flag"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	var addr = flag.String("addr", ":8080", "Address and port to run the server")
+	var migrate = flag.String("migrate", "", "Run schema migrations and exit: up, down or status")
+	var steps = flag.Int("steps", 1, "Number of migrations to roll back with -migrate=down")
 	flag.Parse()
 
 	myEnv, err := godotenv.Read()
@@ -29,6 +33,13 @@ func main() {
 		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		pgUser, pgPassword, pgHost, pgPort, pgDB,
 	)
+	if *migrate != "" {
+		if err := runMigrateCommand(dbURL, *migrate, *steps); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
 	// Initialize database
 	db, err := database.InitDB(dbURL)
 	if err != nil {
@@ -41,6 +52,9 @@ func main() {
 	h := handlers.NewHandler(db, jwtSecret)
 	router := SetupRoutes(h, jwtSecret)
 
+	// Start and end the game on schedule
+	go h.RunScheduler(context.Background(), time.Second)
+
 	// Start server
 	log.Println("Server starting on", *addr)
 	if err := router.Run(*addr); err != nil {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"cyberhunt/internal/services"
+	"errors"
 	"net/http"
 
 	"fmt"
@@ -11,6 +13,29 @@ import (
 
 type Middleware struct {
 	JWTSecret string
+	Sessions  *services.SessionService
+}
+
+// revoked reports whether the session named by the token's jti claim has
+// ended, aborting the request if so. Tokens issued before sessions existed
+// have no jti and must log in again.
+func (m *Middleware) revoked(c *gin.Context, claims jwt.MapClaims, cookie, redirectTo string, check func(sessionID string) error) bool {
+	sessionID, _ := claims["jti"].(string)
+	err := services.ErrSessionRevoked
+	if sessionID != "" {
+		err = check(sessionID)
+	}
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, services.ErrSessionRevoked):
+		c.SetCookie(cookie, "", -1, "/", "", false, true)
+		unauthorized(c, redirectTo)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+	}
+	c.Abort()
+	return true
 }
 
 // AuthMiddleware protects regular users
@@ -43,7 +68,20 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			// Tokens issued before events existed carry no event and must log in again
+			eventIDFloat, ok := claims["eventID"].(float64)
+			if !ok {
+				unauthorized(c, "/login")
+				c.Abort()
+				return
+			}
+			if m.revoked(c, claims, "auth", "/login", func(sessionID string) error {
+				return m.Sessions.CheckGroupSession(c.Request.Context(), sessionID, int(groupIDFloat))
+			}) {
+				return
+			}
 			c.Set("groupID", int(groupIDFloat))
+			c.Set("eventID", int(eventIDFloat))
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
@@ -74,6 +112,7 @@ func (m *Middleware) AdminAuthMiddleware() gin.HandlerFunc {
 
 		if err != nil || !token.Valid {
 			unauthorized(c, "/admin/login")
+			c.Abort()
 			return
 		}
 
@@ -94,6 +133,11 @@ func (m *Middleware) AdminAuthMiddleware() gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			if m.revoked(c, claims, "adminAuth", "/admin/login", func(sessionID string) error {
+				return m.Sessions.CheckAdminSession(c.Request.Context(), sessionID, int(adminIDFloat))
+			}) {
+				return
+			}
 			c.Set("adminID", int(adminIDFloat))
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
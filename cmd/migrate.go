@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"cyberhunt/internal/database"
+	"fmt"
+	"time"
+)
+
+// runMigrateCommand handles the -migrate flag: "up" applies pending
+// migrations, "down" rolls back the last steps migrations and "status"
+// lists every migration and whether it has been applied.
+func runMigrateCommand(dbURL, command string, steps int) error {
+	db, err := database.Open(dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := database.MigrateUp(ctx, db)
+		for _, m := range applied {
+			fmt.Printf("applied   %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		if steps <= 0 {
+			return fmt.Errorf("-steps must be greater than 0")
+		}
+		reverted, err := database.MigrateDown(ctx, db, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+	case "status":
+		status, err := database.GetMigrationStatus(ctx, db)
+		for _, s := range status {
+			if s.AppliedAt != nil {
+				fmt.Printf("applied   %04d_%s (%s)\n", s.Version, s.Name, s.AppliedAt.UTC().Format(time.RFC3339))
+			} else {
+				fmt.Printf("pending   %04d_%s\n", s.Version, s.Name)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown -migrate command %q (expected up, down or status)", command)
+	}
+
+	return nil
+}
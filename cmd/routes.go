@@ -2,6 +2,7 @@ package main
 
 import (
 	"cyberhunt/internal/handlers"
+	"cyberhunt/internal/models"
 	"net/http"
 
 	"github.com/gin-contrib/gzip"
@@ -22,7 +23,7 @@ func SetupRoutes(h *handlers.Handler, jwtSecret string) *gin.Engine {
 	// Routes
 
 	//init authjwt
-	m := Middleware{JWTSecret: jwtSecret}
+	m := Middleware{JWTSecret: jwtSecret, Sessions: h.Sessions()}
 	// Public Routes
 	r.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusFound, "/login")
@@ -34,39 +35,92 @@ func SetupRoutes(h *handlers.Handler, jwtSecret string) *gin.Engine {
 	r.GET("/admin/login", h.AdminLoginPage)
 	r.POST("/admin/login", h.AdminLogin)
 	r.POST("/logout", h.Logout)
+	r.GET("/api/events", h.GetPublicEvents)
 
 	// User Routes (require authentication)
 	r.GET("/game", m.AuthMiddleware(), h.GamePage)
 	r.GET("/leaderboard", m.AuthMiddleware(), h.LeaderboardPage)
 	r.GET("/api/leaderboard/stream", m.AuthMiddleware(), h.LeaderboardStream)
 	r.POST("/api/scan", m.AuthMiddleware(), h.ScanQR)
+	r.POST("/api/submit", m.AuthMiddleware(), h.SubmitAnswer)
 	r.GET("/api/game-partial", m.AuthMiddleware(), h.GamePartial)
+	r.GET("/api/group/timeline", m.AuthMiddleware(), h.GroupTimeline)
+	r.POST("/api/hint", m.AuthMiddleware(), h.RevealHint)
 
 	// Admin Routes
-	r.GET("/admin", m.AdminAuthMiddleware(), h.AdminPage)
-	r.POST("/api/admin/start", m.AdminAuthMiddleware(), h.StartGame)
-	r.POST("/api/admin/end", m.AdminAuthMiddleware(), h.EndGame)
-	r.POST("/api/admin/clear", m.AdminAuthMiddleware(), h.ClearState)
-	r.POST("/api/admin/group", m.AdminAuthMiddleware(), h.AddGroup)
-	r.DELETE("/api/admin/group/:id", m.AdminAuthMiddleware(), h.DeleteGroup)
-	r.GET("/api/admin/status", m.AdminAuthMiddleware(), h.GetGameStatus)
-	r.GET("/api/admin/leaderboard/stream", m.AdminAuthMiddleware(), h.LeaderboardStream)
+	r.GET("/admin", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminPage)
+	r.GET("/api/admin/preflight", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetPreflight)
+	r.POST("/api/admin/start", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.start"), h.StartGame)
+	r.POST("/api/admin/end", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.end"), h.EndGame)
+	r.POST("/api/admin/pause", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.pause"), h.PauseGame)
+	r.POST("/api/admin/resume", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.resume"), h.ResumeGame)
+	r.POST("/api/admin/clear", m.AdminAuthMiddleware(), h.RequirePermission(models.PermResetGame), h.AdminEventMiddleware(), h.Audit("game.clear"), h.ClearState)
+	r.POST("/api/admin/group", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("group.add"), h.AddGroup)
+	r.DELETE("/api/admin/group/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("group.delete"), h.DeleteGroup)
+	r.POST("/api/admin/group/:id/kick", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("group.kick"), h.KickGroup)
+	r.GET("/api/admin/bundle", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.ExportBundle)
+	r.POST("/api/admin/bundle", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.AdminEventMiddleware(), h.Audit("bundle.import"), h.ImportBundle)
+	r.POST("/api/admin/groups/import", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("group.import"), h.ImportGroups)
+	r.GET("/api/admin/groups/export", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.ExportGroups)
+	r.POST("/api/admin/groups/export", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("group.reset_passwords"), h.ExportGroups)
+	r.GET("/api/admin/status", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetGameStatus)
+	r.PUT("/api/admin/schedule", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.schedule"), h.UpdateSchedule)
+	r.GET("/api/admin/leaderboard/stream", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.LeaderboardStream)
+	r.GET("/api/admin/scans", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetScanEvents)
+	r.GET("/api/admin/scan-rules", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetScanRules)
+	r.PUT("/api/admin/scan-rules", m.AdminAuthMiddleware(), h.RequirePermission(models.PermRunGame), h.AdminEventMiddleware(), h.Audit("game.scan_rules"), h.UpdateScanRules)
+
+	// Staff routes
+	r.GET("/api/admin/me", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.GetCurrentAdmin)
+	r.GET("/api/admin/staff", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageStaff), h.GetStaff)
+	r.POST("/api/admin/staff", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageStaff), h.Audit("staff.invite"), h.InviteStaff)
+	r.PUT("/api/admin/staff/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageStaff), h.Audit("staff.update"), h.UpdateStaff)
+	r.POST("/api/admin/staff/:id/password", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageStaff), h.Audit("staff.reset_password"), h.ResetStaffPassword)
+	r.DELETE("/api/admin/staff/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageStaff), h.Audit("staff.remove"), h.RemoveStaff)
+
+	r.GET("/api/admin/audit", m.AdminAuthMiddleware(), h.RequirePermission(models.PermViewAudit), h.GetAuditLog)
+
+	// Event management routes
+	r.GET("/api/admin/events", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.GetEvents)
+	r.POST("/api/admin/events", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.Audit("event.create"), h.CreateEvent)
+	r.PUT("/api/admin/events/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.Audit("event.rename"), h.UpdateEvent)
+	r.POST("/api/admin/events/:id/clone", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.Audit("event.clone"), h.CloneEvent)
+	r.POST("/api/admin/events/:id/archive", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.Audit("event.archive"), h.ArchiveEvent)
+	r.POST("/api/admin/events/:id/restore", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageEvents), h.Audit("event.restore"), h.RestoreEvent)
+
+	// Pathway management routes
+	r.GET("/api/admin/pathways", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetPathways)
+	r.POST("/api/admin/pathways", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("pathway.add"), h.AddPathway)
+	r.PUT("/api/admin/pathways/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("pathway.update"), h.UpdatePathway)
+	r.DELETE("/api/admin/pathways/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("pathway.delete"), h.DeletePathway)
+	r.GET("/api/admin/pathways/:id/graph", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetPathwayGraph)
 
 	// Seed routes
-	r.GET("/seed", m.AdminAuthMiddleware(), h.SeedPage)
-	r.POST("/api/seed/groups", m.AdminAuthMiddleware(), h.SeedGroups)
-	r.POST("/api/seed/clues", m.AdminAuthMiddleware(), h.SeedClues)
-	r.POST("/api/seed/total_clues", m.AdminAuthMiddleware(), h.UpdateTotalClues)
+	r.GET("/seed", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.SeedPage)
+	r.POST("/api/seed/groups", m.AdminAuthMiddleware(), h.RequirePermission(models.PermManageGroups), h.AdminEventMiddleware(), h.Audit("seed.groups"), h.SeedGroups)
+	r.POST("/api/seed/clues", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("seed.clues"), h.SeedClues)
+	r.POST("/api/seed/total_clues", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("seed.total_clues"), h.UpdateTotalClues)
 
 	// Clue management routes
-	r.GET("/api/clues", m.AdminAuthMiddleware(), h.GetAllClues)
-	r.POST("/api/clues", m.AdminAuthMiddleware(), h.AddClue)
-	r.PUT("/api/clues/:id", m.AdminAuthMiddleware(), h.UpdateClue)
-	r.DELETE("/api/clues/:id", m.AdminAuthMiddleware(), h.DeleteClue)
+	r.GET("/api/clues", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetAllClues)
+	r.POST("/api/clues", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("clue.add"), h.AddClue)
+	r.PUT("/api/clues/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("clue.update"), h.UpdateClue)
+	r.DELETE("/api/clues/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("clue.delete"), h.DeleteClue)
+	r.GET("/api/clues/:id/hints", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.AdminEventMiddleware(), h.GetClueHints)
+	r.POST("/api/clues/:id/hints", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("hint.add"), h.AddHint)
+	r.POST("/api/clues/:id/edges", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("clue_edge.add"), h.AddClueEdge)
+	r.DELETE("/api/clues/:id/edges/:to", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("clue_edge.delete"), h.DeleteClueEdge)
+	r.PUT("/api/hints/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("hint.update"), h.UpdateHint)
+	r.DELETE("/api/hints/:id", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("hint.delete"), h.DeleteHint)
 
 	// QR Code routes
-	r.GET("/qr", m.AdminAuthMiddleware(), h.QRPage)
-	r.GET("/api/qr/data", m.AdminAuthMiddleware(), h.GetQRData)
+	r.GET("/qr", m.AdminAuthMiddleware(), h.RequirePermission(models.PermView), h.QRPage)
+	r.GET("/api/qr/data", m.AdminAuthMiddleware(), h.RequirePermission(models.PermPrintQR), h.AdminEventMiddleware(), h.GetQRData)
+	r.POST("/api/qr/sign", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("qr.sign"), h.SignQRCodes)
+	r.POST("/api/qr/rotate", m.AdminAuthMiddleware(), h.RequirePermission(models.PermEditClues), h.AdminEventMiddleware(), h.Audit("qr.rotate"), h.RotateQRSecret)
+	r.GET("/api/qr/sheet.pdf", m.AdminAuthMiddleware(), h.RequirePermission(models.PermPrintQR), h.AdminEventMiddleware(), h.GetQRSheet)
+	r.GET("/api/qr/pathway/:file", m.AdminAuthMiddleware(), h.RequirePermission(models.PermPrintQR), h.AdminEventMiddleware(), h.GetQRArchive)
+	r.GET("/api/qr/:file", m.AdminAuthMiddleware(), h.RequirePermission(models.PermPrintQR), h.AdminEventMiddleware(), h.GetQRImage)
 
 	r.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS pathways;
+DROP TABLE IF EXISTS game_settings;
+DROP TABLE IF EXISTS clues;
+DROP TABLE IF EXISTS groups;
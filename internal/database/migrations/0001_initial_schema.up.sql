@@ -0,0 +1,62 @@
+-- Baseline schema. Written with IF NOT EXISTS so it can also be applied to
+-- databases created before migrations were introduced.
+
+CREATE TABLE IF NOT EXISTS groups (
+	id SERIAL PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL,
+	pathway TEXT NOT NULL,
+	current_clue_idx INTEGER NOT NULL DEFAULT 0,
+	completed BOOLEAN NOT NULL DEFAULT FALSE,
+	end_time TIMESTAMPTZ,
+	password TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS clues (
+	id SERIAL PRIMARY KEY,
+	pathway TEXT NOT NULL,
+	index_num INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	qrcode TEXT NOT NULL,
+	UNIQUE(pathway, index_num)
+);
+
+CREATE TABLE IF NOT EXISTS game_settings (
+	id          INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+	start_time  TIMESTAMPTZ,
+	game_started BOOLEAN NOT NULL DEFAULT FALSE,
+	game_ended   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS pathways (
+	id SERIAL PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL,
+	color TEXT NOT NULL DEFAULT '#6b7280',
+	description TEXT NOT NULL DEFAULT '',
+	active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+ALTER TABLE pathways ADD COLUMN IF NOT EXISTS total_clues INTEGER CHECK (total_clues > 0);
+
+-- Clue totals live on pathways
+ALTER TABLE game_settings DROP COLUMN IF EXISTS total_clues;
+
+CREATE TABLE IF NOT EXISTS admins (
+	id SERIAL PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL,
+	password TEXT NOT NULL
+);
+
+INSERT INTO game_settings (id)
+VALUES (1)
+ON CONFLICT (id) DO NOTHING;
+
+-- Seed the original four tracks on a fresh database
+INSERT INTO pathways (name, color)
+SELECT v.name, v.color
+FROM (VALUES
+	('red', '#ef4444'),
+	('blue', '#3b82f6'),
+	('yellow', '#eab308'),
+	('green', '#22c55e')
+) AS v(name, color)
+WHERE NOT EXISTS (SELECT 1 FROM pathways);
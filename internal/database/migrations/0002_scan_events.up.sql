@@ -0,0 +1,14 @@
+CREATE TABLE scan_events (
+	id BIGSERIAL PRIMARY KEY,
+	group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+	clue_id INTEGER REFERENCES clues(id) ON DELETE SET NULL,
+	clue_idx INTEGER NOT NULL,
+	submitted_code TEXT NOT NULL,
+	result TEXT NOT NULL,
+	client_ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX scan_events_group_created_idx ON scan_events (group_id, created_at);
+CREATE INDEX scan_events_created_idx ON scan_events (created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS penalty_seconds;
+DROP TABLE IF EXISTS hint_reveals;
+DROP TABLE IF EXISTS clue_hints;
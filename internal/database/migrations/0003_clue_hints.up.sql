@@ -0,0 +1,19 @@
+CREATE TABLE clue_hints (
+	id SERIAL PRIMARY KEY,
+	clue_id INTEGER NOT NULL REFERENCES clues(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL CHECK (position >= 0),
+	content TEXT NOT NULL,
+	penalty_seconds INTEGER NOT NULL DEFAULT 0 CHECK (penalty_seconds >= 0),
+	UNIQUE (clue_id, position)
+);
+
+CREATE TABLE hint_reveals (
+	group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+	hint_id INTEGER NOT NULL REFERENCES clue_hints(id) ON DELETE CASCADE,
+	penalty_seconds INTEGER NOT NULL,
+	revealed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (group_id, hint_id)
+);
+
+-- Accumulated time penalty added to the group's total time
+ALTER TABLE groups ADD COLUMN penalty_seconds INTEGER NOT NULL DEFAULT 0;
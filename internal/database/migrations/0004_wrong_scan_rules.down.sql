@@ -0,0 +1,7 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS locked_until;
+
+ALTER TABLE game_settings
+	DROP COLUMN IF EXISTS wrong_scan_penalty_after,
+	DROP COLUMN IF EXISTS wrong_scan_penalty_seconds,
+	DROP COLUMN IF EXISTS lockout_after,
+	DROP COLUMN IF EXISTS lockout_seconds;
@@ -0,0 +1,8 @@
+-- Wrong-scan rules. A value of 0 disables the corresponding rule.
+ALTER TABLE game_settings
+	ADD COLUMN wrong_scan_penalty_after   INTEGER NOT NULL DEFAULT 0 CHECK (wrong_scan_penalty_after >= 0),
+	ADD COLUMN wrong_scan_penalty_seconds INTEGER NOT NULL DEFAULT 0 CHECK (wrong_scan_penalty_seconds >= 0),
+	ADD COLUMN lockout_after              INTEGER NOT NULL DEFAULT 0 CHECK (lockout_after >= 0),
+	ADD COLUMN lockout_seconds            INTEGER NOT NULL DEFAULT 0 CHECK (lockout_seconds >= 0);
+
+ALTER TABLE groups ADD COLUMN locked_until TIMESTAMPTZ;
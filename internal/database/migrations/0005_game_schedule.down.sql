@@ -0,0 +1,3 @@
+ALTER TABLE game_settings
+	DROP COLUMN IF EXISTS scheduled_start,
+	DROP COLUMN IF EXISTS max_duration_seconds;
@@ -0,0 +1,3 @@
+ALTER TABLE game_settings
+	ADD COLUMN scheduled_start      TIMESTAMPTZ,
+	ADD COLUMN max_duration_seconds INTEGER CHECK (max_duration_seconds > 0);
@@ -0,0 +1,10 @@
+-- Intervals during which the game clock was frozen. The open pause, if any,
+-- has no resumed_at.
+CREATE TABLE game_pauses (
+	id SERIAL PRIMARY KEY,
+	paused_at TIMESTAMPTZ NOT NULL,
+	resumed_at TIMESTAMPTZ,
+	CHECK (resumed_at IS NULL OR resumed_at >= paused_at)
+);
+
+CREATE UNIQUE INDEX game_pauses_one_open ON game_pauses ((resumed_at IS NULL)) WHERE resumed_at IS NULL;
@@ -0,0 +1,29 @@
+-- Only the first event survives a rollback; the data of every other event is
+-- deleted.
+DELETE FROM events WHERE id <> (SELECT MIN(id) FROM events);
+
+DROP INDEX IF EXISTS game_pauses_one_open;
+ALTER TABLE game_pauses DROP COLUMN IF EXISTS event_id;
+CREATE UNIQUE INDEX game_pauses_one_open ON game_pauses ((resumed_at IS NULL)) WHERE resumed_at IS NULL;
+
+ALTER TABLE groups
+	DROP CONSTRAINT IF EXISTS groups_event_id_name_key,
+	ADD CONSTRAINT groups_name_key UNIQUE (name),
+	DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE clues
+	DROP CONSTRAINT IF EXISTS clues_event_id_pathway_index_num_key,
+	ADD CONSTRAINT clues_pathway_index_num_key UNIQUE (pathway, index_num),
+	DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE pathways
+	DROP CONSTRAINT IF EXISTS pathways_event_id_name_key,
+	ADD CONSTRAINT pathways_name_key UNIQUE (name),
+	DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE game_settings ADD COLUMN id INTEGER NOT NULL DEFAULT 1 CHECK (id = 1);
+ALTER TABLE game_settings DROP CONSTRAINT game_settings_pkey;
+ALTER TABLE game_settings ADD PRIMARY KEY (id);
+ALTER TABLE game_settings DROP COLUMN event_id;
+
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,46 @@
+-- Events own their settings, pathways, clues and groups so several hunts can
+-- share one deployment. Existing data moves to a first, default event.
+CREATE TABLE events (
+	id SERIAL PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL,
+	archived BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO events (name) VALUES ('Default event');
+
+-- One settings row per event, keyed by the event
+ALTER TABLE game_settings ADD COLUMN event_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+UPDATE game_settings SET event_id = (SELECT MIN(id) FROM events);
+ALTER TABLE game_settings DROP COLUMN id;
+ALTER TABLE game_settings ALTER COLUMN event_id SET NOT NULL;
+ALTER TABLE game_settings ADD PRIMARY KEY (event_id);
+
+-- Names and clue positions are unique within an event only
+ALTER TABLE pathways ADD COLUMN event_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+UPDATE pathways SET event_id = (SELECT MIN(id) FROM events);
+ALTER TABLE pathways
+	ALTER COLUMN event_id SET NOT NULL,
+	DROP CONSTRAINT IF EXISTS pathways_name_key,
+	ADD CONSTRAINT pathways_event_id_name_key UNIQUE (event_id, name);
+
+ALTER TABLE clues ADD COLUMN event_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+UPDATE clues SET event_id = (SELECT MIN(id) FROM events);
+ALTER TABLE clues
+	ALTER COLUMN event_id SET NOT NULL,
+	DROP CONSTRAINT IF EXISTS clues_pathway_index_num_key,
+	ADD CONSTRAINT clues_event_id_pathway_index_num_key UNIQUE (event_id, pathway, index_num);
+
+ALTER TABLE groups ADD COLUMN event_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+UPDATE groups SET event_id = (SELECT MIN(id) FROM events);
+ALTER TABLE groups
+	ALTER COLUMN event_id SET NOT NULL,
+	DROP CONSTRAINT IF EXISTS groups_name_key,
+	ADD CONSTRAINT groups_event_id_name_key UNIQUE (event_id, name);
+
+-- Each event is paused on its own
+ALTER TABLE game_pauses ADD COLUMN event_id INTEGER REFERENCES events(id) ON DELETE CASCADE;
+UPDATE game_pauses SET event_id = (SELECT MIN(id) FROM events);
+ALTER TABLE game_pauses ALTER COLUMN event_id SET NOT NULL;
+DROP INDEX IF EXISTS game_pauses_one_open;
+CREATE UNIQUE INDEX game_pauses_one_open ON game_pauses (event_id) WHERE resumed_at IS NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS group_clue_order;
+
+ALTER TABLE pathways
+	DROP COLUMN IF EXISTS shuffle_clues,
+	DROP COLUMN IF EXISTS pin_first_clue,
+	DROP COLUMN IF EXISTS pin_last_clue;
@@ -0,0 +1,15 @@
+-- Pathways can hand every group its own permutation of their clues, with the
+-- first and/or last clue optionally kept in place.
+ALTER TABLE pathways
+	ADD COLUMN shuffle_clues BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN pin_first_clue BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN pin_last_clue BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The clue a group has to solve at each position, generated at game start
+CREATE TABLE group_clue_order (
+	group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL CHECK (position >= 0),
+	clue_id INTEGER NOT NULL REFERENCES clues(id) ON DELETE CASCADE,
+	PRIMARY KEY (group_id, position),
+	UNIQUE (group_id, clue_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS current_clue_id;
+
+DROP TABLE IF EXISTS clue_edges;
+
+ALTER TABLE pathways DROP COLUMN IF EXISTS mode;
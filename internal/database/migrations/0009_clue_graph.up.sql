@@ -0,0 +1,18 @@
+-- Graph pathways replace the linear index_num sequence with a directed graph
+-- of clues: a group on a clue advances by scanning the code of any clue one
+-- of its edges leads to, and finishes on a clue without outgoing edges.
+ALTER TABLE pathways
+	ADD COLUMN mode TEXT NOT NULL DEFAULT 'linear' CHECK (mode IN ('linear', 'graph'));
+
+CREATE TABLE clue_edges (
+	from_clue_id INTEGER NOT NULL REFERENCES clues(id) ON DELETE CASCADE,
+	to_clue_id INTEGER NOT NULL REFERENCES clues(id) ON DELETE CASCADE,
+	PRIMARY KEY (from_clue_id, to_clue_id),
+	CHECK (from_clue_id <> to_clue_id)
+);
+
+CREATE INDEX clue_edges_to_clue_id_idx ON clue_edges (to_clue_id);
+
+-- The clue a group on a graph pathway is at; NULL means the pathway's start
+ALTER TABLE groups
+	ADD COLUMN current_clue_id INTEGER REFERENCES clues(id) ON DELETE SET NULL;
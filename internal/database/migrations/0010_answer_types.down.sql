@@ -0,0 +1,7 @@
+ALTER TABLE clues
+	DROP CONSTRAINT IF EXISTS clues_correct_choice_check,
+	DROP COLUMN IF EXISTS answer_type,
+	DROP COLUMN IF EXISTS answers,
+	DROP COLUMN IF EXISTS answer_pattern,
+	DROP COLUMN IF EXISTS choices,
+	DROP COLUMN IF EXISTS correct_choice;
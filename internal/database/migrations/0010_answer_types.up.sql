@@ -0,0 +1,11 @@
+-- Clues declare how they are solved: by scanning their QR code, by typing one
+-- of the accepted answers (or a match of answer_pattern), or by picking the
+-- correct one of several choices.
+ALTER TABLE clues
+	ADD COLUMN answer_type TEXT NOT NULL DEFAULT 'qr' CHECK (answer_type IN ('qr', 'text', 'choice')),
+	ADD COLUMN answers TEXT[] NOT NULL DEFAULT '{}',
+	ADD COLUMN answer_pattern TEXT NOT NULL DEFAULT '',
+	ADD COLUMN choices TEXT[] NOT NULL DEFAULT '{}',
+	ADD COLUMN correct_choice INTEGER NOT NULL DEFAULT 0,
+	ADD CONSTRAINT clues_correct_choice_check
+		CHECK (answer_type <> 'choice' OR (correct_choice >= 0 AND correct_choice < cardinality(choices)));
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS scan_events_outside_geofence_idx;
+
+ALTER TABLE scan_events
+	DROP COLUMN IF EXISTS latitude,
+	DROP COLUMN IF EXISTS longitude,
+	DROP COLUMN IF EXISTS accuracy_meters,
+	DROP COLUMN IF EXISTS distance_meters,
+	DROP COLUMN IF EXISTS outside_geofence;
+
+ALTER TABLE clues
+	DROP CONSTRAINT IF EXISTS clues_geofence_check,
+	DROP COLUMN IF EXISTS latitude,
+	DROP COLUMN IF EXISTS longitude,
+	DROP COLUMN IF EXISTS radius_meters,
+	DROP COLUMN IF EXISTS geofence_reject;
@@ -0,0 +1,23 @@
+-- A clue can be tied to the spot its code is posted at. Answers submitted
+-- from further away than radius_meters are flagged, or rejected when
+-- geofence_reject is set.
+ALTER TABLE clues
+	ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+	ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+	ADD COLUMN radius_meters INTEGER CHECK (radius_meters > 0),
+	ADD COLUMN geofence_reject BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD CONSTRAINT clues_geofence_check CHECK (
+		(latitude IS NULL AND longitude IS NULL AND radius_meters IS NULL)
+		OR (latitude IS NOT NULL AND longitude IS NOT NULL AND radius_meters IS NOT NULL)
+	);
+
+-- The device location reported with a submission and, for geofenced clues,
+-- how far it was from the clue
+ALTER TABLE scan_events
+	ADD COLUMN latitude DOUBLE PRECISION,
+	ADD COLUMN longitude DOUBLE PRECISION,
+	ADD COLUMN accuracy_meters DOUBLE PRECISION,
+	ADD COLUMN distance_meters DOUBLE PRECISION,
+	ADD COLUMN outside_geofence BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX scan_events_outside_geofence_idx ON scan_events (created_at) WHERE outside_geofence;
@@ -0,0 +1,3 @@
+-- The key signed QR codes of an event are minted with. It is created on
+-- first use, and replacing it invalidates every code minted before.
+ALTER TABLE events ADD COLUMN qr_secret BYTEA;
@@ -0,0 +1,3 @@
+ALTER TABLE clues
+	DROP COLUMN IF EXISTS checkpoint_label,
+	DROP COLUMN IF EXISTS placement_notes;
@@ -0,0 +1,5 @@
+-- Printed on checkpoint sheets: a label for the spot a clue's code is posted
+-- at and notes for the volunteers putting it up
+ALTER TABLE clues
+	ADD COLUMN checkpoint_label TEXT NOT NULL DEFAULT '',
+	ADD COLUMN placement_notes TEXT NOT NULL DEFAULT '';
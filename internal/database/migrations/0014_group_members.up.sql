@@ -0,0 +1,2 @@
+-- Names of the people in a group, as listed when groups are imported
+ALTER TABLE groups ADD COLUMN members TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+-- Each member of staff has a role limiting what they can do. Existing admins
+-- keep full access; new ones start read-only unless given a role.
+ALTER TABLE admins
+	ADD COLUMN role TEXT NOT NULL DEFAULT 'owner'
+		CHECK (role IN ('owner', 'game_master', 'clue_editor', 'marshal', 'viewer'));
+ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'viewer';
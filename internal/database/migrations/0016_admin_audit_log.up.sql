@@ -0,0 +1,16 @@
+-- Every state-changing admin request, with the values it replaced. The
+-- admin's name is copied so entries survive the admin being removed.
+CREATE TABLE admin_audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+	admin_name TEXT NOT NULL,
+	event_id INTEGER REFERENCES events(id) ON DELETE SET NULL,
+	action TEXT NOT NULL,
+	target_id TEXT NOT NULL DEFAULT '',
+	before JSONB,
+	after JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX admin_audit_log_created_idx ON admin_audit_log (created_at);
+CREATE INDEX admin_audit_log_event_created_idx ON admin_audit_log (event_id, created_at);
@@ -0,0 +1,14 @@
+-- Every login gets a session named by its token's jti claim. Tokens are only
+-- accepted while their session exists, so deleting the row revokes them.
+CREATE TABLE sessions (
+	id TEXT PRIMARY KEY,
+	group_id INTEGER REFERENCES groups(id) ON DELETE CASCADE,
+	admin_id INTEGER REFERENCES admins(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	CHECK ((group_id IS NULL) <> (admin_id IS NULL))
+);
+
+CREATE INDEX sessions_group_idx ON sessions (group_id);
+CREATE INDEX sessions_admin_idx ON sessions (admin_id);
+CREATE INDEX sessions_expires_idx ON sessions (expires_at);
@@ -1,13 +1,12 @@
 package handlers
 
 import (
+	"cyberhunt/internal/models"
 	"cyberhunt/internal/services"
 	"cyberhunt/internal/utils"
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/http"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,8 +18,48 @@ func (h *Handler) AdminPage(c *gin.Context) {
 	c.HTML(http.StatusOK, "admin.html", nil)
 }
 
+// GetPreflight reports problems with the event's setup that would leave
+// groups without a clue once the game starts.
+func (h *Handler) GetPreflight(c *gin.Context) {
+	report, err := h.gameService.Preflight(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check game setup"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": !report.HasErrors(), "issues": report.Issues})
+}
+
+// preflightBlocks runs the preflight checks unless force=true was passed,
+// writing a 409 response with the issues and returning true when they found
+// errors.
+func (h *Handler) preflightBlocks(c *gin.Context) bool {
+	if c.Query("force") == "true" {
+		return false
+	}
+	report, err := h.gameService.Preflight(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check game setup"})
+		return true
+	}
+	if report.HasErrors() {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Game setup has errors, fix them or pass force=true",
+			"issues": report.Issues,
+		})
+		return true
+	}
+	return false
+}
+
+// StartGame starts the game once the preflight checks pass, or regardless
+// of them with force=true.
 func (h *Handler) StartGame(c *gin.Context) {
-	err := h.gameService.StartGame(c.Request.Context())
+	if h.preflightBlocks(c) {
+		return
+	}
+
+	auditBefore(c, h.auditGameState(c))
+	err := h.gameService.StartGame(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		if errors.Is(err, services.ErrGameAlreadyStarted) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Game already started"})
@@ -30,11 +69,17 @@ func (h *Handler) StartGame(c *gin.Context) {
 		return
 	}
 
+	if err := h.BroadcastGameEvent(c.Request.Context(), currentEventID(c), EventGameStarted); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, h.auditGameState(c))
 	c.JSON(http.StatusOK, gin.H{"message": "Game started successfully!"})
 }
 
 func (h *Handler) EndGame(c *gin.Context) {
-	err := h.gameService.EndGame(c.Request.Context())
+	auditBefore(c, h.auditGameState(c))
+	err := h.gameService.EndGame(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		switch err {
 		case services.ErrGameNotStarted:
@@ -50,23 +95,82 @@ func (h *Handler) EndGame(c *gin.Context) {
 		return
 	}
 
+	if err := h.BroadcastGameEvent(c.Request.Context(), currentEventID(c), EventGameEnded); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
 	// Success
+	auditAfter(c, h.auditGameState(c))
 	c.JSON(http.StatusOK, gin.H{"message": "Game ended successfully!"})
 }
 
+// PauseGame freezes the game clock and scanning, e.g. during an evacuation.
+func (h *Handler) PauseGame(c *gin.Context) {
+	auditBefore(c, h.auditGameState(c))
+	err := h.gameService.PauseGame(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGameNotStarted):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Game has not started yet"})
+		case errors.Is(err, services.ErrGameAlreadyEnded):
+			c.JSON(http.StatusConflict, gin.H{"error": "Game already ended"})
+		case errors.Is(err, services.ErrGamePaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Game already paused"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pause game"})
+		}
+		return
+	}
+
+	if err := h.BroadcastGameEvent(c.Request.Context(), currentEventID(c), EventGamePaused); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, h.auditGameState(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Game paused successfully!"})
+}
+
+// ResumeGame restarts the game clock. The paused interval is excluded from
+// every group's time.
+func (h *Handler) ResumeGame(c *gin.Context) {
+	auditBefore(c, h.auditGameState(c))
+	err := h.gameService.ResumeGame(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGameNotPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Game is not paused"})
+		case errors.Is(err, services.ErrGameAlreadyEnded):
+			c.JSON(http.StatusConflict, gin.H{"error": "Game already ended"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume game"})
+		}
+		return
+	}
+
+	if err := h.BroadcastGameEvent(c.Request.Context(), currentEventID(c), EventGameResumed); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, h.auditGameState(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Game resumed successfully!"})
+}
+
 func (h *Handler) ClearState(c *gin.Context) {
-	err := h.gameService.ClearAllState(c.Request.Context())
+	// Keep the progress being wiped so it can be looked up afterwards
+	auditBefore(c, gin.H{"game": h.auditGameState(c), "groups": h.auditGroupProgress(c)})
+	err := h.gameService.ClearAllState(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear state"})
 		return
 	}
 
 	// Broadcast updated leaderboard after clearing state
-	if err := h.BroadcastLeaderboard(c.Request.Context()); err != nil {
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
 		// Log the error but don't fail the request since state was cleared successfully
 		c.Header("X-Warning", "Leaderboard broadcast failed")
 	}
 
+	auditAfter(c, gin.H{"game": h.auditGameState(c), "groups": h.auditGroupProgress(c)})
 	c.JSON(http.StatusOK, gin.H{"message": "Game state cleared successfully!"})
 }
 
@@ -94,20 +198,16 @@ func (h *Handler) AddGroup(c *gin.Context) {
 		return
 	}
 
-	validPathways := []string{"red", "blue", "green", "yellow"}
-	if !slices.Contains(validPathways, pathway) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Invalid pathway. Must be one of: %s", strings.Join(validPathways, ", ")),
-		})
+	if !h.validatePathway(c, pathway, true) {
 		return
 	}
 
 	password := strings.TrimSpace(request.Password)
 	if password == "" {
-		password = utils.GenerateRandomPassword(6)
+		password = utils.GenerateSecurePassword(6)
 	}
 
-	if err := h.groupService.AddGroup(c.Request.Context(), name, pathway, password); err != nil {
+	if err := h.groupService.AddGroup(c.Request.Context(), currentEventID(c), name, pathway, password); err != nil {
 		if errors.Is(err, services.ErrGroupExists) { // <-- check using errors.Is
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		} else {
@@ -117,17 +217,21 @@ func (h *Handler) AddGroup(c *gin.Context) {
 	}
 
 	// Broadcast updated leaderboard after adding group
-	if err := h.BroadcastLeaderboard(c.Request.Context()); err != nil {
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
 		// Log the error but don't fail the request since group was added successfully
 		c.Header("X-Warning", "Leaderboard broadcast failed")
 	}
 
+	auditAfter(c, gin.H{"name": name, "pathway": pathway})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Group added successfully!",
 		"password": password,
 	})
 }
 
+// DeleteGroup removes a group. Its sessions are removed with it, so the
+// group is logged out straight away.
 func (h *Handler) DeleteGroup(c *gin.Context) {
 	groupIDStr := c.Param("id")
 	groupID, err := strconv.Atoi(groupIDStr)
@@ -136,7 +240,18 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.DeleteGroup(c.Request.Context(), groupID)
+	if group, err := h.groupService.GetGroupByID(c.Request.Context(), groupID); err == nil && group.EventID == currentEventID(c) {
+		auditBefore(c, gin.H{
+			"name":             group.Name,
+			"pathway":          group.Pathway,
+			"current_clue_idx": group.CurrentClueIdx,
+			"completed":        group.Completed,
+			"end_time":         group.EndTime,
+			"penalty_seconds":  group.PenaltySeconds,
+		})
+	}
+
+	err = h.groupService.DeleteGroup(c.Request.Context(), currentEventID(c), groupID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
@@ -146,7 +261,7 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	if err := h.BroadcastLeaderboard(c.Request.Context()); err != nil {
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
 		// Log the error but don't fail the request since group was added successfully
 		c.Header("X-Warning", "Leaderboard broadcast failed")
 	}
@@ -154,8 +269,31 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully!"})
 }
 
+// KickGroup logs a group out on every device. The group can log in again
+// with its password, so reset the password too to keep it out.
+func (h *Handler) KickGroup(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || groupID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	n, err := h.sessionService.RevokeGroupSessions(c.Request.Context(), currentEventID(c), groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to kick group"})
+		return
+	}
+
+	auditAfter(c, gin.H{"sessions_revoked": n})
+	c.JSON(http.StatusOK, gin.H{"message": "Group kicked successfully!", "sessions_revoked": n})
+}
+
 func (h *Handler) GetGameStatus(c *gin.Context) {
-	settings, err := h.gameService.GetGameStatus(c.Request.Context())
+	settings, err := h.gameService.GetGameStatus(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get game status",
@@ -164,13 +302,126 @@ func (h *Handler) GetGameStatus(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"game_started": settings.GameStarted,
-		"game_ended":   settings.GameEnded,
+		"game_started":   settings.GameStarted,
+		"game_ended":     settings.GameEnded,
+		"paused":         settings.Paused(),
+		"paused_seconds": settings.PausedSeconds,
 	}
 
 	if settings.StartTime != nil {
 		response["start_time"] = settings.StartTime.UTC().Format(time.RFC3339)
 	}
+	if settings.PausedAt != nil {
+		response["paused_at"] = settings.PausedAt.UTC().Format(time.RFC3339)
+	}
+	if settings.ScheduledStart != nil {
+		response["scheduled_start"] = settings.ScheduledStart.UTC().Format(time.RFC3339)
+	}
+	if settings.MaxDurationSeconds != nil {
+		response["max_duration_seconds"] = *settings.MaxDurationSeconds
+	}
+	if endsAt := settings.EndsAt(); endsAt != nil {
+		response["ends_at"] = endsAt.UTC().Format(time.RFC3339)
+	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *Handler) GetScanRules(c *gin.Context) {
+	settings, err := h.gameService.GetGameStatus(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"penalty_after":   settings.ScanRules.PenaltyAfter,
+		"penalty_seconds": settings.ScanRules.PenaltySeconds,
+		"lockout_after":   settings.ScanRules.LockoutAfter,
+		"lockout_seconds": settings.ScanRules.LockoutSeconds,
+	})
+}
+
+func (h *Handler) UpdateScanRules(c *gin.Context) {
+	var request struct {
+		PenaltyAfter   int `json:"penalty_after"`
+		PenaltySeconds int `json:"penalty_seconds"`
+		LockoutAfter   int `json:"lockout_after"`
+		LockoutSeconds int `json:"lockout_seconds"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if request.PenaltyAfter < 0 || request.PenaltySeconds < 0 || request.LockoutAfter < 0 || request.LockoutSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scan rule values must be greater than or equal to 0"})
+		return
+	}
+
+	if settings, err := h.gameService.GetGameStatus(c.Request.Context(), currentEventID(c)); err == nil {
+		auditBefore(c, settings.ScanRules)
+	}
+	rules := models.ScanRules{
+		PenaltyAfter:   request.PenaltyAfter,
+		PenaltySeconds: request.PenaltySeconds,
+		LockoutAfter:   request.LockoutAfter,
+		LockoutSeconds: request.LockoutSeconds,
+	}
+	err := h.gameService.UpdateScanRules(c.Request.Context(), currentEventID(c), rules)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update scan rules"})
+		return
+	}
+
+	auditAfter(c, rules)
+	c.JSON(http.StatusOK, gin.H{"message": "Scan rules updated successfully!"})
+}
+
+// UpdateSchedule sets when the game starts on its own and how long it may
+// run before it is ended automatically. Null values clear the setting.
+func (h *Handler) UpdateSchedule(c *gin.Context) {
+	var request struct {
+		ScheduledStart     *time.Time `json:"scheduled_start"`
+		MaxDurationMinutes *int       `json:"max_duration_minutes"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request, scheduled_start must be RFC3339"})
+		return
+	}
+
+	// Nobody is around to check the setup when a scheduled game starts
+	if request.ScheduledStart != nil && h.preflightBlocks(c) {
+		return
+	}
+
+	var maxDurationSeconds *int
+	if request.MaxDurationMinutes != nil {
+		if *request.MaxDurationMinutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum duration must be greater than 0"})
+			return
+		}
+		seconds := *request.MaxDurationMinutes * 60
+		maxDurationSeconds = &seconds
+	}
+
+	auditBefore(c, h.auditGameState(c))
+	err := h.gameService.UpdateSchedule(c.Request.Context(), currentEventID(c), request.ScheduledStart, maxDurationSeconds)
+	if err != nil {
+		if errors.Is(err, services.ErrGameAlreadyStarted) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Game already started"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, h.auditGameState(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule updated successfully!"})
+}
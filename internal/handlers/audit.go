@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditBefore records what an audited request is about to change.
+func auditBefore(c *gin.Context, v any) {
+	c.Set("auditBefore", v)
+}
+
+// auditAfter records what an audited request changed things to.
+func auditAfter(c *gin.Context, v any) {
+	c.Set("auditAfter", v)
+}
+
+// auditTarget overrides the target of an audited request, which is the id
+// path parameter by default, e.g. with the ID of something just created.
+func auditTarget(c *gin.Context, id int) {
+	c.Set("auditTarget", strconv.Itoa(id))
+}
+
+// skipAudit stops a request that turned out to change nothing, such as a dry
+// run, from being recorded.
+func skipAudit(c *gin.Context) {
+	c.Set("auditSkip", true)
+}
+
+// auditGameState snapshots the settings the game controls change, or returns
+// nil if they cannot be read.
+func (h *Handler) auditGameState(c *gin.Context) any {
+	s, err := h.gameService.GetGameStatus(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		return nil
+	}
+	return gin.H{
+		"game_started":         s.GameStarted,
+		"game_ended":           s.GameEnded,
+		"start_time":           s.StartTime,
+		"paused_at":            s.PausedAt,
+		"scheduled_start":      s.ScheduledStart,
+		"max_duration_seconds": s.MaxDurationSeconds,
+	}
+}
+
+// auditGroupProgress snapshots how far every group of the event has got, or
+// returns nil if it cannot be read.
+func (h *Handler) auditGroupProgress(c *gin.Context) any {
+	groups, err := h.groupService.GetGroupsForLeaderboard(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		return nil
+	}
+	out := make([]gin.H, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, gin.H{
+			"id":               g.ID,
+			"name":             g.Name,
+			"pathway":          g.Pathway,
+			"current_clue_idx": g.CurrentClueIdx,
+			"completed":        g.Completed,
+			"end_time":         g.EndTime,
+		})
+	}
+	return out
+}
+
+// auditPathway snapshots a pathway of the event, or returns nil if it cannot
+// be read.
+func (h *Handler) auditPathway(c *gin.Context, id int) any {
+	pathways, err := h.pathwayService.GetAllPathways(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		return nil
+	}
+	for _, p := range pathways {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// Audit records the request in the audit log under action once the handler
+// has succeeded, along with whatever the handler passed to auditBefore and
+// auditAfter. It must run after RequirePermission, and after
+// AdminEventMiddleware on routes that have it.
+func (h *Handler) Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.IsAborted() || c.Writer.Status() >= http.StatusMultipleChoices || c.GetBool("auditSkip") {
+			return
+		}
+
+		entry := models.AuditEntry{Action: action, TargetID: c.Param("id")}
+		if id := c.GetInt("adminID"); id > 0 {
+			entry.AdminID = &id
+		}
+		if admin := currentAdmin(c); admin != nil {
+			entry.AdminName = admin.Name
+		}
+		if id := currentEventID(c); id > 0 {
+			entry.EventID = &id
+		}
+		if target := c.GetString("auditTarget"); target != "" {
+			entry.TargetID = target
+		}
+		for key, dst := range map[string]*json.RawMessage{"auditBefore": &entry.Before, "auditAfter": &entry.After} {
+			v, ok := c.Get(key)
+			if !ok || v == nil {
+				continue
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				log.Printf("audit %s: encode %s: %v", action, key, err)
+				continue
+			}
+			*dst = b
+		}
+
+		// The change has been made, so record it even if the client has gone
+		ctx := context.WithoutCancel(c.Request.Context())
+		if err := h.auditService.Record(ctx, &entry); err != nil {
+			log.Printf("audit %s by admin %d: %v", action, c.GetInt("adminID"), err)
+		}
+	}
+}
+
+// GetAuditLog lists audit entries, newest first, filtered by event_id,
+// admin_id, action, target_id, since and until.
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	filter := services.AuditFilter{
+		Action:   c.Query("action"),
+		TargetID: c.Query("target_id"),
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	}
+	if filter.EventID, ok = parseIDQuery(c, "event_id"); !ok {
+		return
+	}
+	if filter.AdminID, ok = parseIDQuery(c, "admin_id"); !ok {
+		return
+	}
+	if filter.Since, ok = parseTimeQuery(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = parseTimeQuery(c, "until"); !ok {
+		return
+	}
+
+	entries, total, err := h.auditService.ListAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	out := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, gin.H{
+			"id":         e.ID,
+			"admin_id":   e.AdminID,
+			"admin_name": e.AdminName,
+			"event_id":   e.EventID,
+			"action":     e.Action,
+			"target_id":  e.TargetID,
+			"before":     e.Before,
+			"after":      e.After,
+			"created_at": e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":   out,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
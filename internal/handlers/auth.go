@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +18,80 @@ func (h *Handler) LoginPage(c *gin.Context) {
 	c.HTML(http.StatusOK, "login.html", nil)
 }
 
+// loginEvent resolves the event a group logs in to: the event_id form field,
+// or the only event open for logins when it is omitted.
+func (h *Handler) loginEvent(ctx context.Context, raw string) (*models.Event, error) {
+	if raw == "" {
+		events, err := h.eventService.ListEvents(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		switch len(events) {
+		case 0:
+			return nil, services.ErrEventNotFound
+		case 1:
+			return events[0], nil
+		default:
+			return nil, services.ErrEventRequired
+		}
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return nil, services.ErrEventNotFound
+	}
+	event, err := h.eventService.GetEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Archived {
+		return nil, services.ErrEventArchived
+	}
+	return event, nil
+}
+
 func (h *Handler) Login(c *gin.Context) {
 	name := c.PostForm("name")
 	password := c.PostForm("password")
 
-	group, err := h.groupService.GetGroupByNameAndPassword(c.Request.Context(), name, password)
+	event, err := h.loginEvent(c.Request.Context(), c.PostForm("event_id"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid login!"})
+		switch {
+		case errors.Is(err, services.ErrEventRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Please select an event"})
+		case errors.Is(err, services.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		case errors.Is(err, services.ErrEventArchived):
+			c.JSON(http.StatusForbidden, gin.H{"error": "This event has been archived"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	group, err := h.groupService.GetGroupByNameAndPassword(c.Request.Context(), event.ID, name, password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid login!"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	sessionID, err := h.sessionService.CreateGroupSession(c.Request.Context(), group.ID, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
 	// Create JWT token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"groupID": group.ID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"eventID": group.EventID,
+		"jti":     sessionID,
+		"exp":     expiresAt.Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(h.jwtSecret))
@@ -52,7 +118,18 @@ func (h *Handler) AdminLogin(c *gin.Context) {
 
 	admin, err := h.adminService.GetAdminByNameAndPassword(c.Request.Context(), name, password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid login!"})
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid login!"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	sessionID, err := h.sessionService.CreateAdminSession(c.Request.Context(), admin.ID, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
@@ -60,7 +137,8 @@ func (h *Handler) AdminLogin(c *gin.Context) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"adminID": admin.ID,
 		"isAdmin": true,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"jti":     sessionID,
+		"exp":     expiresAt.Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(h.jwtSecret))
@@ -77,7 +155,42 @@ func (h *Handler) AdminLogin(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+// Logout ends the sessions of both cookies, so copies of the tokens stop
+// working too.
 func (h *Handler) Logout(c *gin.Context) {
+	sessionIDs := []string{h.cookieSessionID(c, "auth"), h.cookieSessionID(c, "adminAuth")}
+
 	c.SetCookie("auth", "", -1, "/", "", false, true)
 	c.SetCookie("adminAuth", "", -1, "/", "", false, true)
+
+	for _, sessionID := range sessionIDs {
+		if sessionID == "" {
+			continue
+		}
+		if err := h.sessionService.RevokeSession(c.Request.Context(), sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+	}
+}
+
+// cookieSessionID returns the session of the token in the named cookie, or
+// "" if there is no valid token.
+func (h *Handler) cookieSessionID(c *gin.Context, name string) string {
+	tokenString, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(h.jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return ""
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	sessionID, _ := claims["jti"].(string)
+	return sessionID
 }
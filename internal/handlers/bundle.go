@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"bytes"
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"cyberhunt/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleVersion is the version of the hunt bundle format written by
+// ExportBundle. Bundles of any other version are rejected.
+const bundleVersion = 1
+
+// maxBundleSize caps the size of an uploaded bundle.
+const maxBundleSize = 8 << 20
+
+// bundle is the file format of a hunt: its rules, pathways with their clues
+// and hints, and optionally its groups. YAML bundles use the same keys.
+type bundle struct {
+	Version  int             `json:"version"`
+	Settings bundleSettings  `json:"settings"`
+	Pathways []bundlePathway `json:"pathways"`
+	// Groups replace the event's groups on import with replace_groups=true.
+	// They are left out of exports unless include_groups=true.
+	Groups []bundleGroup `json:"groups,omitempty"`
+}
+
+type bundleSettings struct {
+	PenaltyAfter       int  `json:"penalty_after"`
+	PenaltySeconds     int  `json:"penalty_seconds"`
+	LockoutAfter       int  `json:"lockout_after"`
+	LockoutSeconds     int  `json:"lockout_seconds"`
+	MaxDurationSeconds *int `json:"max_duration_seconds"`
+}
+
+type bundlePathway struct {
+	pathwayRequest
+	Clues []bundleClue `json:"clues"`
+}
+
+type bundleClue struct {
+	Index   int    `json:"index"`
+	Content string `json:"content"`
+	QRCode  string `json:"qrcode"`
+	clueAnswerRequest
+	clueGeofenceRequest
+	clueCheckpointRequest
+	Hints []hintRequest `json:"hints"`
+	// Next lists the index of every clue on the same graph pathway this
+	// clue leads to.
+	Next []int `json:"next"`
+}
+
+type bundleGroup struct {
+	Name     string   `json:"name"`
+	Pathway  string   `json:"pathway"`
+	Password string   `json:"password,omitempty"`
+	Members  []string `json:"members"`
+}
+
+// bundleProblem is something wrong with a bundle. Problems stop the import,
+// warnings are reported but do not.
+type bundleProblem struct {
+	Where string `json:"where"`
+	Error string `json:"error"`
+}
+
+// decodeBundle reads a JSON or YAML bundle. YAML is converted to JSON first,
+// so both formats share the JSON keys and unknown keys are rejected in both.
+func decodeBundle(data []byte) (*bundle, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bundle
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML, keeping the key
+// order.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var unstyle func(n *yaml.Node)
+	unstyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			unstyle(child)
+		}
+	}
+	unstyle(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hunt validates the bundle and converts it for import. keptGroups are the
+// pathways of the event's current groups, which must still exist when the
+// bundle leaves its groups out. A bundle with groups is only accepted when
+// replaceGroups is set, since importing it deletes the current groups.
+func (b *bundle) hunt(keptGroups map[string]string, replaceGroups bool) (*services.Hunt, []bundleProblem, []bundleProblem) {
+	var problems, warnings []bundleProblem
+	report := func(where, format string, args ...any) {
+		problems = append(problems, bundleProblem{Where: where, Error: fmt.Sprintf(format, args...)})
+	}
+	warn := func(where, format string, args ...any) {
+		warnings = append(warnings, bundleProblem{Where: where, Error: fmt.Sprintf(format, args...)})
+	}
+
+	if b.Version != bundleVersion {
+		report("version", "Unsupported bundle version %d, expected %d", b.Version, bundleVersion)
+		return nil, problems, warnings
+	}
+
+	s := b.Settings
+	hunt := &services.Hunt{
+		ScanRules: models.ScanRules{
+			PenaltyAfter:   s.PenaltyAfter,
+			PenaltySeconds: s.PenaltySeconds,
+			LockoutAfter:   s.LockoutAfter,
+			LockoutSeconds: s.LockoutSeconds,
+		},
+	}
+	if s.PenaltyAfter < 0 || s.PenaltySeconds < 0 || s.LockoutAfter < 0 || s.LockoutSeconds < 0 {
+		report("settings", "Scan rule values must be greater than or equal to 0")
+	}
+	if s.MaxDurationSeconds != nil {
+		if *s.MaxDurationSeconds <= 0 {
+			report("settings", "Maximum duration must be greater than 0")
+		}
+		seconds := *s.MaxDurationSeconds
+		hunt.MaxDurationSeconds = &seconds
+	}
+
+	pathways := map[string]bool{}
+	for i := range b.Pathways {
+		bp := &b.Pathways[i]
+		where := fmt.Sprintf("pathways[%d]", i)
+		if msg := bp.normalize(); msg != "" {
+			report(where, "%s", msg)
+			continue
+		}
+		where = "pathways." + bp.Name
+		if pathways[bp.Name] {
+			report(where, "Pathway %s appears more than once", bp.Name)
+			continue
+		}
+		pathways[bp.Name] = true
+
+		hp := services.HuntPathway{Pathway: models.Pathway{
+			Name:        bp.Name,
+			Color:       bp.Color,
+			Description: bp.Description,
+			Active:      *bp.Active,
+			TotalClues:  bp.TotalClues,
+			ClueOrder:   bp.clueOrder(),
+			Mode:        bp.Mode,
+		}}
+
+		indexes := map[int]bool{}
+		for j := range bp.Clues {
+			bc := &bp.Clues[j]
+			where := fmt.Sprintf("%s.clues[%d]", where, j)
+			hc, ok := bc.clue(where, report)
+			if indexes[bc.Index] {
+				report(where, "Duplicate index %d", bc.Index)
+				ok = false
+			}
+			indexes[bc.Index] = true
+			if ok {
+				hp.Clues = append(hp.Clues, hc)
+			}
+		}
+
+		// Linear pathways are walked by index, so the indexes must run
+		// from 0 without gaps up to the configured length. Clues past it
+		// are allowed but never played, as in the preflight check
+		if bp.Mode == models.PathwayModeLinear {
+			for idx := range len(bp.Clues) {
+				if !indexes[idx] {
+					report(where, "Missing clue with index %d", idx)
+				}
+			}
+			if total := bp.TotalClues; total != nil && *total > len(bp.Clues) {
+				report(where, "Pathway has %d clues but total_clues is %d", len(bp.Clues), *total)
+			} else if total != nil && *total < len(bp.Clues) {
+				warn(where, "Pathway has %d clues but only %d are played", len(bp.Clues), *total)
+			}
+		}
+		for j, bc := range bp.Clues {
+			for k, to := range bc.Next {
+				switch {
+				case bp.Mode != models.PathwayModeGraph:
+					report(fmt.Sprintf("%s.clues[%d]", where, j), "Only clues on graph pathways can have next clues")
+				case to == bc.Index || !indexes[to]:
+					report(fmt.Sprintf("%s.clues[%d]", where, j), "Next clue %d is not another clue on this pathway", to)
+				case slices.Contains(bc.Next[:k], to):
+					report(fmt.Sprintf("%s.clues[%d]", where, j), "Next clue %d is listed more than once", to)
+				}
+			}
+		}
+
+		hunt.Pathways = append(hunt.Pathways, hp)
+	}
+
+	if b.Groups != nil && !replaceGroups {
+		report("groups", "Bundle has groups, which would replace the event's groups; import with replace_groups=true to allow this")
+	}
+	if b.Groups != nil {
+		hunt.Groups = []services.NewGroup{}
+		names := map[string]bool{}
+		for i, bg := range b.Groups {
+			where := fmt.Sprintf("groups[%d]", i)
+			g := services.NewGroup{
+				Name:     strings.TrimSpace(bg.Name),
+				Pathway:  strings.ToLower(strings.TrimSpace(bg.Pathway)),
+				Password: strings.TrimSpace(bg.Password),
+				Members:  bg.Members,
+			}
+			switch {
+			case g.Name == "":
+				report(where, "Group name is required")
+			case names[g.Name]:
+				report(where, "Group %s appears more than once", g.Name)
+			case !pathways[g.Pathway]:
+				report(where, "Group %s is on unknown pathway %q", g.Name, g.Pathway)
+			}
+			names[g.Name] = true
+			if g.Password == "" {
+				g.Password = utils.GenerateSecurePassword(6)
+			}
+			hunt.Groups = append(hunt.Groups, g)
+		}
+	} else {
+		names := slices.Sorted(maps.Keys(keptGroups))
+		for _, name := range names {
+			if pathway := keptGroups[name]; !pathways[pathway] {
+				report("groups", "Existing group %s is on pathway %s, which the bundle removes", name, pathway)
+			}
+		}
+	}
+
+	return hunt, problems, warnings
+}
+
+// clue validates a bundled clue with the same rules as AddClue.
+func (bc *bundleClue) clue(where string, report func(where, format string, args ...any)) (services.HuntClue, bool) {
+	ok := true
+	fail := func(format string, args ...any) {
+		report(where, format, args...)
+		ok = false
+	}
+
+	hc := services.HuntClue{
+		Clue: models.Clue{
+			Index:      bc.Index,
+			Content:    strings.TrimSpace(bc.Content),
+			QRCode:     strings.TrimSpace(bc.QRCode),
+			Answer:     bc.answer(),
+			Checkpoint: bc.checkpoint(),
+		},
+		Next: bc.Next,
+	}
+	if bc.Index < 0 {
+		fail("Index must be greater than or equal to 0")
+	}
+	if hc.Content == "" {
+		fail("Clue content is required")
+	}
+	if err := services.NormalizeClueAnswer(&hc.Answer); err != nil {
+		fail("%s", err.Error())
+	}
+	fence, msg := bc.geofence()
+	if msg != "" {
+		fail("%s", msg)
+	}
+	hc.Geofence = fence
+
+	positions := map[int]bool{}
+	for k, hr := range bc.Hints {
+		position := k
+		if hr.Position != nil {
+			position = *hr.Position
+		}
+		content := strings.TrimSpace(hr.Content)
+		switch {
+		case content == "":
+			fail("Hint %d has no content", k)
+		case position < 0:
+			fail("Hint %d: position must be greater than or equal to 0", k)
+		case positions[position]:
+			fail("Hint %d: another hint is already at position %d", k, position)
+		case hr.PenaltySeconds < 0:
+			fail("Hint %d: penalty must be greater than or equal to 0", k)
+		}
+		positions[position] = true
+		hc.Hints = append(hc.Hints, models.Hint{Position: position, Content: content, PenaltySeconds: hr.PenaltySeconds})
+	}
+
+	return hc, ok
+}
+
+// newBundle converts an exported hunt to the bundle format. Groups are only
+// included with includeGroups.
+func newBundle(hunt *services.Hunt, includeGroups bool) *bundle {
+	b := &bundle{
+		Version: bundleVersion,
+		Settings: bundleSettings{
+			PenaltyAfter:   hunt.ScanRules.PenaltyAfter,
+			PenaltySeconds: hunt.ScanRules.PenaltySeconds,
+			LockoutAfter:   hunt.ScanRules.LockoutAfter,
+			LockoutSeconds: hunt.ScanRules.LockoutSeconds,
+		},
+		Pathways: []bundlePathway{},
+	}
+	if hunt.MaxDurationSeconds != nil {
+		seconds := *hunt.MaxDurationSeconds
+		b.Settings.MaxDurationSeconds = &seconds
+	}
+
+	for _, p := range hunt.Pathways {
+		active := p.Active
+		bp := bundlePathway{
+			pathwayRequest: pathwayRequest{
+				Name:         p.Name,
+				Color:        p.Color,
+				Description:  p.Description,
+				Active:       &active,
+				TotalClues:   p.TotalClues,
+				ShuffleClues: p.ClueOrder.Shuffle,
+				PinFirst:     p.ClueOrder.PinFirst,
+				PinLast:      p.ClueOrder.PinLast,
+				Mode:         p.Mode,
+			},
+			Clues: []bundleClue{},
+		}
+		for _, c := range p.Clues {
+			bc := bundleClue{
+				Index:   c.Index,
+				Content: c.Content,
+				QRCode:  c.QRCode,
+				clueAnswerRequest: clueAnswerRequest{
+					AnswerType:    c.Answer.Type,
+					Answers:       c.Answer.Answers,
+					AnswerPattern: c.Answer.Pattern,
+					Choices:       c.Answer.Choices,
+					CorrectChoice: c.Answer.CorrectChoice,
+				},
+				clueCheckpointRequest: clueCheckpointRequest{
+					CheckpointLabel: c.Checkpoint.Label,
+					PlacementNotes:  c.Checkpoint.PlacementNotes,
+				},
+				Hints: []hintRequest{},
+				Next:  c.Next,
+			}
+			if f := c.Geofence; f != nil {
+				lat, lng, radius := f.Latitude, f.Longitude, f.RadiusMeters
+				bc.clueGeofenceRequest = clueGeofenceRequest{
+					Latitude: &lat, Longitude: &lng, RadiusMeters: &radius, GeofenceReject: f.Reject,
+				}
+			}
+			for _, h := range c.Hints {
+				position := h.Position
+				bc.Hints = append(bc.Hints, hintRequest{Position: &position, Content: h.Content, PenaltySeconds: h.PenaltySeconds})
+			}
+			bp.Clues = append(bp.Clues, bc)
+		}
+		b.Pathways = append(b.Pathways, bp)
+	}
+
+	if !includeGroups {
+		return b
+	}
+	b.Groups = []bundleGroup{}
+	for _, g := range hunt.Groups {
+		members := g.Members
+		if members == nil {
+			members = []string{}
+		}
+		b.Groups = append(b.Groups, bundleGroup{Name: g.Name, Pathway: g.Pathway, Members: members})
+	}
+	return b
+}
+
+// ExportBundle downloads the event's hunt as a JSON bundle, or as YAML with
+// format=yaml. Groups are included with include_groups=true, but never their
+// passwords.
+func (h *Handler) ExportBundle(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "yaml" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be json or yaml"})
+		return
+	}
+
+	hunt, err := h.eventService.ExportHunt(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export hunt"})
+		return
+	}
+
+	data, err := json.MarshalIndent(newBundle(hunt, c.Query("include_groups") == "true"), "", "  ")
+	contentType := "application/json"
+	if err == nil && format == "yaml" {
+		data, err = jsonToYAML(data)
+		contentType = "application/yaml"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode bundle"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="hunt.%s"`, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ImportBundle replaces the event's hunt with an uploaded JSON or YAML
+// bundle in one transaction. With dry_run=true the bundle is only checked.
+// The event's groups are kept unless replace_groups=true, which is required
+// for a bundle that has groups.
+// Every problem found is reported and nothing is changed unless there are
+// none.
+func (h *Handler) ImportBundle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBundleSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read bundle: " + err.Error()})
+		return
+	}
+	b, err := decodeBundle(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bundle: " + err.Error()})
+		return
+	}
+
+	current, err := h.groupService.ListGroups(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+	kept := make(map[string]string, len(current))
+	for _, g := range current {
+		kept[g.Name] = g.Pathway
+	}
+
+	hunt, problems, warnings := b.hunt(kept, c.Query("replace_groups") == "true")
+	if problems == nil {
+		problems = []bundleProblem{}
+	}
+	if warnings == nil {
+		warnings = []bundleProblem{}
+	}
+
+	summary := gin.H{"pathways": 0, "clues": 0, "hints": 0, "groups": len(current)}
+	if hunt != nil {
+		clues, hints := 0, 0
+		for _, p := range hunt.Pathways {
+			clues += len(p.Clues)
+			for _, clue := range p.Clues {
+				hints += len(clue.Hints)
+			}
+		}
+		summary["pathways"], summary["clues"], summary["hints"] = len(hunt.Pathways), clues, hints
+		if hunt.Groups != nil {
+			summary["groups"] = len(hunt.Groups)
+		}
+	}
+
+	if c.Query("dry_run") == "true" {
+		skipAudit(c)
+		c.JSON(http.StatusOK, gin.H{"valid": len(problems) == 0, "problems": problems, "warnings": warnings, "summary": summary})
+		return
+	}
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bundle", "problems": problems})
+		return
+	}
+
+	// Exported hunts leave passwords out, so these are safe to keep
+	if before, err := h.eventService.ExportHunt(ctx, currentEventID(c)); err == nil {
+		auditBefore(c, newBundle(before, true))
+	}
+
+	if err := h.eventService.ImportHunt(ctx, currentEventID(c), hunt); err != nil {
+		switch {
+		case errors.Is(err, services.ErrGameAlreadyStarted):
+			c.JSON(http.StatusConflict, gin.H{"error": "Game already started"})
+		case errors.Is(err, services.ErrGroupExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import hunt"})
+		}
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(ctx, currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	if after, err := h.eventService.ExportHunt(ctx, currentEventID(c)); err == nil {
+		auditAfter(c, newBundle(after, true))
+	}
+
+	response := gin.H{"message": "Hunt imported successfully!", "summary": summary, "warnings": warnings}
+	if hunt.Groups != nil {
+		groups := make([]gin.H, 0, len(hunt.Groups))
+		for _, g := range hunt.Groups {
+			groups = append(groups, gin.H{"name": g.Name, "pathway": g.Pathway, "password": g.Password})
+		}
+		response["groups"] = groups
+	}
+	c.JSON(http.StatusOK, response)
+}
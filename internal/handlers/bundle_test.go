@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBundleTotalClues(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalClues string
+		problems   int
+		warnings   int
+	}{
+		{"not set", "null", 0, 0},
+		{"every clue played", "3", 0, 0},
+		{"fewer than the clues", "2", 0, 1},
+		{"more than the clues", "4", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := decodeBundle(fmt.Appendf(nil, `{"version": 1, "pathways": [{
+				"name": "red", "active": true, "mode": "linear", "total_clues": %s,
+				"clues": [
+					{"index": 0, "content": "a", "qrcode": "A"},
+					{"index": 1, "content": "b", "qrcode": "B"},
+					{"index": 2, "content": "c", "qrcode": "C"}
+				]
+			}]}`, tt.totalClues))
+			if err != nil {
+				t.Fatalf("decodeBundle: %v", err)
+			}
+			hunt, problems, warnings := b.hunt(nil, false)
+			if len(problems) != tt.problems || len(warnings) != tt.warnings {
+				t.Fatalf("problems %v and warnings %v, want %d and %d", problems, warnings, tt.problems, tt.warnings)
+			}
+			if hunt == nil || len(hunt.Pathways) != 1 || len(hunt.Pathways[0].Clues) != 3 {
+				t.Errorf("hunt = %+v, want one pathway with 3 clues", hunt)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPathwayGraph returns the clue graph of a pathway with every clue's
+// distance to the finish.
+func (h *Handler) GetPathwayGraph(c *gin.Context) {
+	pathwayID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || pathwayID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+		return
+	}
+
+	pathways, err := h.pathwayService.GetAllPathways(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	var pathway *models.Pathway
+	for _, p := range pathways {
+		if p.ID == pathwayID {
+			pathway = p
+		}
+	}
+	if pathway == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pathway not found"})
+		return
+	}
+
+	graph, err := h.clueService.GetClueGraph(c.Request.Context(), currentEventID(c), pathway.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clue graph"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pathway": pathway.Name, "mode": pathway.Mode, "graph": graph})
+}
+
+func (h *Handler) AddClueEdge(c *gin.Context) {
+	fromID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || fromID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+
+	var request struct {
+		ToClueID int `json:"to_clue_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	err = h.clueService.AddClueEdge(c.Request.Context(), currentEventID(c), fromID, request.ToClueID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrClueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue not found"})
+		case errors.Is(err, services.ErrInvalidClueEdge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrClueEdgeExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add clue edge"})
+		}
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, gin.H{"from_clue_id": fromID, "to_clue_id": request.ToClueID})
+	c.JSON(http.StatusCreated, gin.H{"message": "Clue edge added successfully!"})
+}
+
+func (h *Handler) DeleteClueEdge(c *gin.Context) {
+	fromID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || fromID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+	toID, err := strconv.Atoi(c.Param("to"))
+	if err != nil || toID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+
+	err = h.clueService.DeleteClueEdge(c.Request.Context(), currentEventID(c), fromID, toID)
+	if err != nil {
+		if errors.Is(err, services.ErrClueEdgeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue edge not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete clue edge"})
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditBefore(c, gin.H{"from_clue_id": fromID, "to_clue_id": toID})
+	c.JSON(http.StatusOK, gin.H{"message": "Clue edge deleted successfully!"})
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminEventCookie holds the event an admin is working on.
+const adminEventCookie = "adminEvent"
+
+// currentEventID returns the event the request is scoped to, as set by
+// AuthMiddleware for groups and AdminEventMiddleware for admins.
+func currentEventID(c *gin.Context) int {
+	return c.GetInt("eventID")
+}
+
+// AdminEventMiddleware resolves the event admin requests operate on: the
+// event_id query parameter, then the adminEvent cookie, then the default
+// event.
+func (h *Handler) AdminEventMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.Query("event_id")
+		if raw == "" {
+			raw, _ = c.Cookie(adminEventCookie)
+		}
+
+		var event *models.Event
+		var err error
+		if raw != "" {
+			id, convErr := strconv.Atoi(raw)
+			if convErr != nil || id <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+				return
+			}
+			event, err = h.eventService.GetEvent(c.Request.Context(), id)
+		} else {
+			event, err = h.eventService.DefaultEvent(c.Request.Context())
+		}
+		if err != nil {
+			if errors.Is(err, services.ErrEventNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve event"})
+			return
+		}
+
+		c.Set("eventID", event.ID)
+		c.Next()
+	}
+}
+
+func eventResponse(e *models.Event) gin.H {
+	return gin.H{
+		"id":         e.ID,
+		"name":       e.Name,
+		"archived":   e.Archived,
+		"created_at": e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// GetPublicEvents lists the events groups can log in to.
+func (h *Handler) GetPublicEvents(c *gin.Context) {
+	events, err := h.eventService.ListEvents(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	out := make([]gin.H, 0, len(events))
+	for _, e := range events {
+		out = append(out, gin.H{"id": e.ID, "name": e.Name})
+	}
+	c.JSON(http.StatusOK, gin.H{"events": out})
+}
+
+func (h *Handler) GetEvents(c *gin.Context) {
+	events, err := h.eventService.ListEvents(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	out := make([]gin.H, 0, len(events))
+	for _, e := range events {
+		out = append(out, eventResponse(e))
+	}
+
+	response := gin.H{"events": out}
+	if def, err := h.eventService.DefaultEvent(c.Request.Context()); err == nil {
+		response["default_event_id"] = def.ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type eventRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *Handler) CreateEvent(c *gin.Context) {
+	var request eventRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event name is required"})
+		return
+	}
+
+	id, err := h.eventService.CreateEvent(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, services.ErrEventExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+		return
+	}
+
+	auditTarget(c, id)
+	auditAfter(c, gin.H{"name": name})
+	c.JSON(http.StatusCreated, gin.H{"message": "Event created successfully!", "id": id})
+}
+
+// CloneEvent creates a new event with the pathways, clues, hints and
+// settings of an existing one, and optionally its groups.
+func (h *Handler) CloneEvent(c *gin.Context) {
+	sourceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || sourceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var request struct {
+		Name          string `json:"name" binding:"required"`
+		IncludeGroups bool   `json:"include_groups"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event name is required"})
+		return
+	}
+
+	id, err := h.eventService.CloneEvent(c.Request.Context(), sourceID, name, request.IncludeGroups)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		case errors.Is(err, services.ErrEventExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone event"})
+		}
+		return
+	}
+
+	auditTarget(c, id)
+	auditAfter(c, gin.H{"name": name, "source_event_id": sourceID, "include_groups": request.IncludeGroups})
+	c.JSON(http.StatusCreated, gin.H{"message": "Event cloned successfully!", "id": id})
+}
+
+func (h *Handler) UpdateEvent(c *gin.Context) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || eventID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var request eventRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event name is required"})
+		return
+	}
+
+	if event, err := h.eventService.GetEvent(c.Request.Context(), eventID); err == nil {
+		auditBefore(c, gin.H{"name": event.Name})
+	}
+
+	err = h.eventService.RenameEvent(c.Request.Context(), eventID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		case errors.Is(err, services.ErrEventExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		}
+		return
+	}
+
+	auditAfter(c, gin.H{"name": name})
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully!"})
+}
+
+// ArchiveEvent ends the event's game if it is running and hides the event
+// from group logins.
+func (h *Handler) ArchiveEvent(c *gin.Context) {
+	h.setEventArchived(c, true)
+}
+
+// RestoreEvent makes an archived event available again.
+func (h *Handler) RestoreEvent(c *gin.Context) {
+	h.setEventArchived(c, false)
+}
+
+func (h *Handler) setEventArchived(c *gin.Context, archived bool) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || eventID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if event, err := h.eventService.GetEvent(c.Request.Context(), eventID); err == nil {
+		auditBefore(c, gin.H{"archived": event.Archived})
+	}
+
+	err = h.eventService.SetArchived(c.Request.Context(), eventID, archived)
+	if err != nil {
+		if errors.Is(err, services.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), eventID); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+	// Viewers got the final standings above; nothing more will happen
+	if archived {
+		h.closeLeaderboardHub(eventID)
+	}
+
+	auditAfter(c, gin.H{"archived": archived})
+	if archived {
+		c.JSON(http.StatusOK, gin.H{"message": "Event archived successfully!"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Event restored successfully!"})
+	}
+}
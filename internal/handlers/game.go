@@ -1,13 +1,50 @@
 package handlers
 
 import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Codes returned to players while the game does not accept their actions.
+const (
+	gameStateRunning    = "running"
+	gameStateNotStarted = "game_not_started"
+	gameStateEnded      = "game_ended"
+	gameStatePaused     = "game_paused"
+)
+
+// gameStateResponse maps a game state error from the services to its code
+// and the message shown to players. ok is false for any other error.
+func gameStateResponse(err error) (code, message string, ok bool) {
+	switch {
+	case errors.Is(err, services.ErrGameNotStarted):
+		return gameStateNotStarted, "The game has not started yet.", true
+	case errors.Is(err, services.ErrGameAlreadyEnded):
+		return gameStateEnded, "The game has ended.", true
+	case errors.Is(err, services.ErrGamePaused):
+		return gameStatePaused, "The game is paused.", true
+	}
+	return "", "", false
+}
+
+// abortGameState replies with 409 and a machine-readable code when err is a
+// game state error, and reports whether it did.
+func abortGameState(c *gin.Context, err error) bool {
+	code, message, ok := gameStateResponse(err)
+	if !ok {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"success": false, "error": message, "message": message, "code": code})
+	return true
+}
+
 func (h *Handler) GamePage(c *gin.Context) {
 	groupID, _ := c.Get("groupID")
 
@@ -17,62 +54,161 @@ func (h *Handler) GamePage(c *gin.Context) {
 		return
 	}
 
-	// Get total clues
-	totalClues, _ := h.gameService.GetTotalClues(c.Request.Context())
+	// Get total clues for the group's pathway
+	totalClues, _ := h.pathwayService.GetTotalClues(c.Request.Context(), group.EventID, group.Pathway)
 
-	// Get current clue if not completed
+	// Get current clue if not completed and the game is running
 	var clueContent string
-	if !group.Completed {
-		clue, err := h.clueService.GetClueByPathwayAndIndex(c.Request.Context(), group.Pathway, group.CurrentClueIdx)
+	var answer models.ClueAnswer
+	stateErr := h.gameService.CheckGameActive(c.Request.Context(), group.EventID)
+	if !group.Completed && stateErr != nil {
+		if _, message, ok := gameStateResponse(stateErr); ok {
+			clueContent = message
+		} else {
+			clueContent = "Failed to load game status"
+		}
+	} else if !group.Completed {
+		clue, err := h.clueService.GetCurrentClue(c.Request.Context(), group)
 		if err != nil {
 			clueContent = "No clue found!"
 		} else {
 			clueContent = clue.Content
+			answer = clue.Answer
 		}
 	} else {
 		clueContent = "Congratulations! You finished! Check out the leaderboard to see your timing!"
 	}
 
+	var pathwayColor string
+	if pathway, err := h.pathwayService.GetPathwayByName(c.Request.Context(), group.EventID, group.Pathway); err == nil {
+		pathwayColor = pathway.Color
+	}
+
 	c.HTML(http.StatusOK, "game.html", gin.H{
-		"Group":      group,
-		"TotalClues": totalClues,
-		"Clue":       clueContent,
+		"Group":        group,
+		"TotalClues":   totalClues,
+		"Clue":         clueContent,
+		"AnswerType":   answer.Type,
+		"Choices":      answer.Choices,
+		"PathwayColor": pathwayColor,
 	})
 }
 
+// locationRequest is the device location the browser reports with a
+// submission.
+type locationRequest struct {
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Accuracy  *float64 `json:"accuracy"`
+}
+
+// scanLocation validates an optional reported location.
+func scanLocation(loc *locationRequest) (*services.ScanLocation, bool) {
+	if loc == nil {
+		return nil, true
+	}
+	if loc.Latitude < -90 || loc.Latitude > 90 || loc.Longitude < -180 || loc.Longitude > 180 {
+		return nil, false
+	}
+	if loc.Accuracy != nil && *loc.Accuracy < 0 {
+		return nil, false
+	}
+	return &services.ScanLocation{
+		Latitude:       loc.Latitude,
+		Longitude:      loc.Longitude,
+		AccuracyMeters: loc.Accuracy,
+	}, true
+}
+
+// ScanQR submits a scanned QR code for the group's current clue.
 func (h *Handler) ScanQR(c *gin.Context) {
-	groupIDRaw, ok := c.Get("groupID")
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Group ID missing"})
-		return
+	var req struct {
+		Code     string           `json:"code" binding:"required"`
+		Location *locationRequest `json:"location"`
 	}
-	groupID, ok := groupIDRaw.(int)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid group ID type"})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	h.submitAnswer(c, strings.TrimSpace(req.Code), req.Location, "QR code")
+}
 
+// SubmitAnswer submits a scanned code, typed answer or choice index for the
+// group's current clue, checked according to the clue's answer type.
+func (h *Handler) SubmitAnswer(c *gin.Context) {
 	var req struct {
-		Code string `json:"code" binding:"required"`
+		Answer   string           `json:"answer" binding:"required"`
+		Location *locationRequest `json:"location"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	h.submitAnswer(c, strings.TrimSpace(req.Answer), req.Location, "answer")
+}
 
-	totalClues, err := h.gameService.GetTotalClues(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch game settings"})
+// submitAnswer records the submission and replies with the outcome; noun
+// names what was submitted in the messages shown to players.
+func (h *Handler) submitAnswer(c *gin.Context, answer string, loc *locationRequest, noun string) {
+	groupIDRaw, ok := c.Get("groupID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Group ID missing"})
+		return
+	}
+	groupID, ok := groupIDRaw.(int)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid group ID type"})
+		return
+	}
+
+	location, ok := scanLocation(loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location"})
 		return
 	}
 
-	g, err := h.groupService.ScanAndUpdateProgress(c.Request.Context(), groupID, strings.TrimSpace(req.Code), totalClues)
+	meta := services.ScanMetadata{ClientIP: c.ClientIP(), UserAgent: c.Request.UserAgent(), Location: location}
+	g, err := h.groupService.SubmitAnswer(c.Request.Context(), groupID, answer, meta)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid QR code") {
-			c.JSON(http.StatusOK, gin.H{"success": false, "message": "Wrong QR code"})
+		if abortGameState(c, err) {
 			return
 		}
-		if strings.Contains(err.Error(), "already completed") {
+		var fenceErr *services.GeofenceError
+		if errors.As(err, &fenceErr) {
+			response := gin.H{"success": false, "message": "You need to be at the checkpoint to submit this " + noun + "."}
+			if fenceErr.DistanceMeters == nil {
+				response["message"] = "Share your location to submit this " + noun + "."
+			} else {
+				response["distanceMeters"] = int(*fenceErr.DistanceMeters)
+			}
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		if errors.Is(err, services.ErrGroupLocked) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success":     false,
+				"message":     "Too many wrong answers. Submitting is locked for a while.",
+				"lockedUntil": g.LockedUntil.UTC().Format(time.RFC3339),
+			})
+			return
+		}
+		var wrongErr *services.WrongAnswerError
+		if errors.As(err, &wrongErr) {
+			response := gin.H{"success": false, "message": "Wrong " + noun, "penaltySeconds": g.PenaltySeconds}
+			if g.LockedUntil != nil && g.LockedUntil.After(time.Now()) {
+				response["message"] = "Wrong " + noun + ". Too many wrong answers, submitting is locked for a while."
+				response["lockedUntil"] = g.LockedUntil.UTC().Format(time.RFC3339)
+			}
+			// Penalties change the ranking
+			if wrongErr.PenaltySeconds > 0 {
+				if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+					c.Header("X-Warning", "Leaderboard broadcast failed")
+				}
+			}
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		if errors.Is(err, services.ErrGroupCompleted) {
 			c.JSON(http.StatusOK, gin.H{"success": true, "message": "Group already completed"})
 			return
 		}
@@ -80,14 +216,14 @@ func (h *Handler) ScanQR(c *gin.Context) {
 		return
 	}
 
-	if err := h.BroadcastLeaderboard(c.Request.Context()); err != nil {
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
 		// Log the error but don't fail the request since group was added successfully
 		c.Header("X-Warning", "Leaderboard broadcast failed")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Correct QR code",
+		"message": "Correct " + noun,
 		"group": gin.H{
 			"Name":           g.Name,
 			"Pathway":        g.Pathway,
@@ -106,30 +242,71 @@ func (h *Handler) GamePartial(c *gin.Context) {
 		return
 	}
 
-	totalClues, err := h.gameService.GetTotalClues(c.Request.Context())
+	totalClues, err := h.pathwayService.GetTotalClues(c.Request.Context(), group.EventID, group.Pathway)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch game settings"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathway settings"})
 		return
 	}
 
+	gameState := gameStateRunning
+	stateErr := h.gameService.CheckGameActive(c.Request.Context(), group.EventID)
+	if stateErr != nil {
+		code, _, ok := gameStateResponse(stateErr)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game status"})
+			return
+		}
+		gameState = code
+	}
+
 	var clueContent string
-	if !group.Completed {
-		clue, err := h.clueService.GetClueByPathwayAndIndex(c.Request.Context(), group.Pathway, group.CurrentClueIdx)
+	answerType := ""
+	choices := []string{}
+	hints := []gin.H{}
+	hintsRemaining := 0
+	if !group.Completed && stateErr != nil {
+		_, clueContent, _ = gameStateResponse(stateErr)
+	} else if !group.Completed {
+		clue, err := h.clueService.GetCurrentClue(c.Request.Context(), group)
 		if err != nil {
 			clueContent = "No clue found!"
 		} else {
 			clueContent = clue.Content
+			answerType = clue.Answer.Type
+			if answerType == models.AnswerTypeChoice {
+				choices = clue.Answer.Choices
+			}
+			hints, hintsRemaining, err = h.revealedHints(c, group.ID, clue.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hints"})
+				return
+			}
 		}
 	} else {
 		clueContent = "Congratulations! You finished! Check out the leaderboard to see your timing!"
 	}
 
 	// Return JSON instead of HTML
-	c.JSON(http.StatusOK, gin.H{
-		"progress":    fmt.Sprintf("%d/%d", group.CurrentClueIdx, totalClues),
-		"completed":   group.Completed,
-		"clue":        clueContent,
-		"totalClues":  totalClues,
-		"currentClue": group.CurrentClueIdx,
-	})
+	response := gin.H{
+		"progress":       fmt.Sprintf("%d/%d", group.CurrentClueIdx, totalClues),
+		"completed":      group.Completed,
+		"clue":           clueContent,
+		"answerType":     answerType,
+		"choices":        choices,
+		"totalClues":     totalClues,
+		"currentClue":    group.CurrentClueIdx,
+		"hints":          hints,
+		"hintsRemaining": hintsRemaining,
+		"penaltySeconds": group.PenaltySeconds,
+		"locked":         false,
+		"gameState":      gameState,
+	}
+	if group.LockedUntil != nil {
+		if remaining := time.Until(*group.LockedUntil); remaining > 0 {
+			response["locked"] = true
+			response["lockedUntil"] = group.LockedUntil.UTC().Format(time.RFC3339)
+			response["lockoutRemainingSeconds"] = int(remaining.Seconds()) + 1
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bytes"
+	"cyberhunt/internal/services"
+	"cyberhunt/internal/utils"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGroupCSVSize caps the size of an uploaded group CSV.
+const maxGroupCSVSize = 1 << 20
+
+// groupCSVColumns is the column order of exported files, and of imported
+// files without a header row.
+var groupCSVColumns = []string{"name", "pathway", "password", "members"}
+
+// groupCSVError reports a problem with one line of an imported file.
+type groupCSVError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// readGroupCSV returns the uploaded file, sent either as the "file" field of
+// a multipart form or as the request body.
+func readGroupCSV(c *gin.Context) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxGroupCSVSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+	return io.ReadAll(c.Request.Body)
+}
+
+// parseGroupCSV turns the records of a group CSV into groups. A first row
+// naming the columns may list them in any order; members are separated by
+// semicolons. Lines that cannot be used are reported rather than skipped.
+func parseGroupCSV(records [][]string, activePathways []string, existing map[string]bool) ([]services.NewGroup, []groupCSVError) {
+	columns := map[string]int{}
+	for i, name := range groupCSVColumns {
+		columns[name] = i
+	}
+	first := 0
+	if len(records) > 0 {
+		header := map[string]int{}
+		for i, name := range records[0] {
+			header[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := header["name"]; ok {
+			columns, first = header, 1
+		}
+	}
+	if _, ok := columns["pathway"]; !ok {
+		return nil, []groupCSVError{{Line: 1, Error: "Missing pathway column"}}
+	}
+
+	active := make(map[string]bool, len(activePathways))
+	for _, p := range activePathways {
+		active[p] = true
+	}
+
+	var groups []services.NewGroup
+	var problems []groupCSVError
+	seen := map[string]int{}
+	for i := first; i < len(records); i++ {
+		record, line := records[i], i+1
+		field := func(name string) string {
+			if col, ok := columns[name]; ok && col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		g := services.NewGroup{
+			Name:     field("name"),
+			Pathway:  strings.ToLower(field("pathway")),
+			Password: field("password"),
+		}
+		for _, m := range strings.Split(field("members"), ";") {
+			if m = strings.TrimSpace(m); m != "" {
+				g.Members = append(g.Members, m)
+			}
+		}
+
+		switch {
+		case g.Name == "":
+			problems = append(problems, groupCSVError{Line: line, Error: "Group name is required"})
+		case existing[g.Name]:
+			problems = append(problems, groupCSVError{Line: line, Error: "Group " + g.Name + " already exists"})
+		case seen[g.Name] > 0:
+			problems = append(problems, groupCSVError{Line: line, Error: "Group " + g.Name + " is also on another line"})
+		case g.Pathway == "":
+			problems = append(problems, groupCSVError{Line: line, Error: "Pathway is required"})
+		case !active[g.Pathway]:
+			problems = append(problems, groupCSVError{Line: line, Error: "Invalid pathway. Must be one of: " + strings.Join(activePathways, ", ")})
+		}
+		seen[g.Name]++
+
+		if g.Password == "" {
+			g.Password = utils.GenerateSecurePassword(6)
+		}
+		groups = append(groups, g)
+	}
+	return groups, problems
+}
+
+// ImportGroups creates groups in bulk from a CSV file with name, pathway,
+// password and members columns. Nothing is created unless every line is
+// valid; the response lists the passwords, including generated ones.
+func (h *Handler) ImportGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	data, err := readGroupCSV(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV: " + err.Error()})
+		return
+	}
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV: " + err.Error()})
+		return
+	}
+
+	pathways, err := h.pathwayService.GetActivePathwayNames(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	current, err := h.groupService.ListGroups(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+	existing := make(map[string]bool, len(current))
+	for _, g := range current {
+		existing[g.Name] = true
+	}
+
+	groups, problems := parseGroupCSV(records, pathways, existing)
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rows in CSV", "rows": problems})
+		return
+	}
+	if len(groups) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV contains no groups"})
+		return
+	}
+
+	if err := h.groupService.ImportGroups(ctx, currentEventID(c), groups); err != nil {
+		if errors.Is(err, services.ErrGroupExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import groups"})
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(ctx, currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	out := make([]gin.H, 0, len(groups))
+	imported := make([]gin.H, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, gin.H{"name": g.Name, "pathway": g.Pathway, "password": g.Password})
+		imported = append(imported, gin.H{"name": g.Name, "pathway": g.Pathway, "members": g.Members})
+	}
+	auditAfter(c, imported)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Groups imported successfully!",
+		"count":   len(groups),
+		"groups":  out,
+	})
+}
+
+// ExportGroups downloads the event's groups as CSV in the format
+// ImportGroups reads. Only password hashes are stored, so a GET leaves the
+// password column empty; a POST gives every group a new password and
+// includes it for printing.
+func (h *Handler) ExportGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	groups, err := h.groupService.ListGroups(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	passwords := map[int]string{}
+	if c.Request.Method == http.MethodPost {
+		for _, g := range groups {
+			passwords[g.ID] = utils.GenerateSecurePassword(6)
+		}
+		if err := h.groupService.ResetGroupPasswords(ctx, currentEventID(c), passwords); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset passwords"})
+			return
+		}
+		auditAfter(c, gin.H{"passwords_reset": len(passwords)})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(groupCSVColumns)
+	for _, g := range groups {
+		w.Write([]string{g.Name, g.Pathway, passwords[g.ID], strings.Join(g.Members, "; ")})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write CSV"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="groups.csv"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
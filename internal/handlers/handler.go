@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"context"
 	"cyberhunt/internal/services"
 	"database/sql"
-	"log"
+	"sync"
 )
 
 type Handler struct {
@@ -12,8 +11,17 @@ type Handler struct {
 	gameService    *services.GameService
 	clueService    *services.ClueService
 	adminService   *services.AdminService
-	LeaderboardHub *LeaderboardHub
+	pathwayService *services.PathwayService
+	scanService    *services.ScanService
+	hintService    *services.HintService
+	eventService   *services.EventService
+	auditService   *services.AuditService
+	sessionService *services.SessionService
 	jwtSecret      string
+
+	// One leaderboard stream per watched event
+	hubsMu sync.Mutex
+	hubs   map[int]*LeaderboardHub
 }
 
 func NewHandler(db *sql.DB, jwtSecret string) *Handler {
@@ -22,16 +30,20 @@ func NewHandler(db *sql.DB, jwtSecret string) *Handler {
 		gameService:    services.NewGameService(db),
 		clueService:    services.NewClueService(db),
 		adminService:   services.NewAdminService(db),
+		pathwayService: services.NewPathwayService(db),
+		scanService:    services.NewScanService(db),
+		hintService:    services.NewHintService(db),
+		eventService:   services.NewEventService(db),
+		auditService:   services.NewAuditService(db),
+		sessionService: services.NewSessionService(db),
 		jwtSecret:      jwtSecret,
-		LeaderboardHub: NewLeaderboardHub(),
+		hubs:           make(map[int]*LeaderboardHub),
 	}
-
-	// Prime the cache once at startup so new SSE clients see data immediately
-	go func() {
-		if err := h.BroadcastLeaderboard(context.Background()); err != nil {
-			log.Printf("initial leaderboard broadcast failed: %v", err)
-		}
-	}()
-
 	return h
 }
+
+// Sessions returns the session store the handlers log groups and admins in
+// with, which the auth middleware must check tokens against.
+func (h *Handler) Sessions() *services.SessionService {
+	return h.sessionService
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"cyberhunt/internal/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type hintRequest struct {
+	Position       *int   `json:"position"`
+	Content        string `json:"content" binding:"required"`
+	PenaltySeconds int    `json:"penalty_seconds"`
+}
+
+// revealedHints returns the hints of clueID already revealed to the group and
+// how many are still hidden.
+func (h *Handler) revealedHints(c *gin.Context, groupID, clueID int) ([]gin.H, int, error) {
+	revealed, err := h.hintService.GetRevealedHints(c.Request.Context(), groupID, clueID)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := h.hintService.CountHints(c.Request.Context(), clueID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]gin.H, 0, len(revealed))
+	for _, hint := range revealed {
+		out = append(out, gin.H{
+			"content":        hint.Content,
+			"penaltySeconds": hint.PenaltySeconds,
+		})
+	}
+	return out, total - len(revealed), nil
+}
+
+// RevealHint reveals the next hint of the group's current clue and charges
+// its time penalty.
+func (h *Handler) RevealHint(c *gin.Context) {
+	groupID, _ := c.Get("groupID")
+
+	hint, err := h.hintService.RevealNextHint(c.Request.Context(), groupID.(int))
+	if err != nil {
+		if abortGameState(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrNoMoreHints):
+			c.JSON(http.StatusOK, gin.H{"success": false, "message": "No more hints for this clue"})
+		case errors.Is(err, services.ErrGroupCompleted):
+			c.JSON(http.StatusOK, gin.H{"success": false, "message": "Group already completed"})
+		case errors.Is(err, services.ErrClueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No clue found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reveal hint"})
+		}
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"hint": gin.H{
+			"content":        hint.Content,
+			"penaltySeconds": hint.PenaltySeconds,
+		},
+	})
+}
+
+func (h *Handler) GetClueHints(c *gin.Context) {
+	clueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || clueID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+
+	hints, err := h.hintService.GetHintsForClue(c.Request.Context(), currentEventID(c), clueID)
+	if err != nil {
+		if errors.Is(err, services.ErrClueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hints"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"hints": hints})
+}
+
+func (h *Handler) AddHint(c *gin.Context) {
+	clueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || clueID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+
+	var request hintRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hint content is required"})
+		return
+	}
+	if request.PenaltySeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Penalty must be greater than or equal to 0"})
+		return
+	}
+
+	// Without an explicit position the hint is appended after the last one
+	position := 0
+	if request.Position != nil {
+		position = *request.Position
+	} else {
+		existing, err := h.hintService.GetHintsForClue(c.Request.Context(), currentEventID(c), clueID)
+		if errors.Is(err, services.ErrClueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hints"})
+			return
+		}
+		if n := len(existing); n > 0 {
+			position = existing[n-1].Position + 1
+		}
+	}
+	if position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Position must be greater than or equal to 0"})
+		return
+	}
+
+	err = h.hintService.AddHint(c.Request.Context(), currentEventID(c), clueID, position, content, request.PenaltySeconds)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrClueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue not found"})
+		case errors.Is(err, services.ErrHintExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add hint"})
+		}
+		return
+	}
+
+	auditAfter(c, gin.H{"position": position, "content": content, "penalty_seconds": request.PenaltySeconds})
+	c.JSON(http.StatusCreated, gin.H{"message": "Hint added successfully!"})
+}
+
+func (h *Handler) UpdateHint(c *gin.Context) {
+	hintID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || hintID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hint ID"})
+		return
+	}
+
+	var request hintRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hint content is required"})
+		return
+	}
+	if request.Position == nil || *request.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Position must be greater than or equal to 0"})
+		return
+	}
+	if request.PenaltySeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Penalty must be greater than or equal to 0"})
+		return
+	}
+
+	if hint, err := h.hintService.GetHint(c.Request.Context(), currentEventID(c), hintID); err == nil {
+		auditBefore(c, hint)
+	}
+
+	err = h.hintService.UpdateHint(c.Request.Context(), currentEventID(c), hintID, *request.Position, content, request.PenaltySeconds)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrHintNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hint not found"})
+		case errors.Is(err, services.ErrHintExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hint"})
+		}
+		return
+	}
+
+	if hint, err := h.hintService.GetHint(c.Request.Context(), currentEventID(c), hintID); err == nil {
+		auditAfter(c, hint)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Hint updated successfully!"})
+}
+
+func (h *Handler) DeleteHint(c *gin.Context) {
+	hintID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || hintID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hint ID"})
+		return
+	}
+
+	if hint, err := h.hintService.GetHint(c.Request.Context(), currentEventID(c), hintID); err == nil {
+		auditBefore(c, hint)
+	}
+
+	err = h.hintService.DeleteHint(c.Request.Context(), currentEventID(c), hintID)
+	if err != nil {
+		if errors.Is(err, services.ErrHintNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete hint"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hint deleted successfully!"})
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"context"
+	"cyberhunt/internal/models"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"sync"
 	"time"
@@ -21,90 +23,220 @@ type LeaderboardEntry struct {
 	Name           string  `json:"name"`
 	Pathway        string  `json:"pathway"`
 	CurrentClueIdx int     `json:"current_clue_idx"`
+	TotalClues     int     `json:"total_clues"`
 	Completed      bool    `json:"completed"`
-	TotalTime      *string `json:"total_time,omitempty"` // null if ongoing
+	TotalTime      *string `json:"total_time,omitempty"` // null if ongoing, includes penalties
+	PenaltyTime    *string `json:"penalty_time,omitempty"`
 	Badge          string  `json:"badge,omitempty"`
+
+	Splits               []SplitEntry `json:"splits,omitempty"`
+	CurrentClueStartedAt *string      `json:"current_clue_started_at,omitempty"` // null once completed
+	CurrentClueTime      *string      `json:"current_clue_time,omitempty"`
 }
 
 type LeaderboardPayload struct {
-	Groups      []LeaderboardEntry `json:"groups"`
-	TotalClues  int                `json:"totalClues"`
-	TotalGroups int                `json:"totalGroups"`
-	Completed   int                `json:"completed"`
-	InProgress  int                `json:"inProgress"`
+	Groups        []LeaderboardEntry `json:"groups"`
+	PathwayTotals map[string]int     `json:"pathwayTotals"`
+	TotalGroups   int                `json:"totalGroups"`
+	Completed     int                `json:"completed"`
+	InProgress    int                `json:"inProgress"`
+	Game          GameState          `json:"game"`
+}
+
+// GameState is the game lifecycle as seen by SSE clients. It is embedded in
+// every leaderboard snapshot and sent on its own with lifecycle events.
+type GameState struct {
+	Started        bool    `json:"started"`
+	Ended          bool    `json:"ended"`
+	Paused         bool    `json:"paused"`
+	PausedAt       *string `json:"paused_at,omitempty"`
+	PausedSeconds  int     `json:"paused_seconds"`
+	StartTime      *string `json:"start_time,omitempty"`
+	ScheduledStart *string `json:"scheduled_start,omitempty"`
+	EndsAt         *string `json:"ends_at,omitempty"`
+}
+
+func newGameState(settings *models.GameSettings) GameState {
+	state := GameState{
+		Started:       settings.GameStarted,
+		Ended:         settings.GameEnded,
+		Paused:        settings.Paused(),
+		PausedSeconds: settings.PausedSeconds,
+	}
+	format := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		s := t.UTC().Format(time.RFC3339)
+		return &s
+	}
+	state.PausedAt = format(settings.PausedAt)
+	state.StartTime = format(settings.StartTime)
+	state.ScheduledStart = format(settings.ScheduledStart)
+	state.EndsAt = format(settings.EndsAt())
+	return state
 }
 
 //
 // ==== Hub Implementation ====
 //
 
+// hubMessage is a fully formatted SSE frame. Snapshots replace the cached
+// frame sent to newly connected clients; named events are only delivered to
+// clients connected at the time.
+type hubMessage struct {
+	frame    []byte
+	snapshot bool
+}
+
 type LeaderboardHub struct {
 	mu        sync.RWMutex
 	clients   map[chan []byte]struct{}
-	broadcast chan []byte
+	broadcast chan hubMessage
 	cache     []byte
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewLeaderboardHub() *LeaderboardHub {
 	h := &LeaderboardHub{
 		clients:   make(map[chan []byte]struct{}),
-		broadcast: make(chan []byte, 1),
+		broadcast: make(chan hubMessage, 1),
+		done:      make(chan struct{}),
 	}
 	go h.run()
 	return h
 }
 
 func (h *LeaderboardHub) run() {
-	for payload := range h.broadcast {
+	for {
+		select {
+		case <-h.done:
+			return
+		case msg := <-h.broadcast:
+			h.mu.Lock()
+			if msg.snapshot {
+				h.cache = msg.frame
+			}
+			for ch := range h.clients {
+				select {
+				case ch <- msg.frame:
+				default: // drop if client is slow
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the hub and ends the stream of every connected client.
+// Messages sent to a closed hub are discarded.
+func (h *LeaderboardHub) Close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
 		h.mu.Lock()
-		h.cache = payload
 		for ch := range h.clients {
-			select {
-			case ch <- payload:
-			default: // drop if client is slow
-			}
+			delete(h.clients, ch)
+			close(ch)
 		}
 		h.mu.Unlock()
-	}
+	})
 }
 
+// Broadcast sends a leaderboard snapshot to every client and caches it for
+// clients that connect later.
 func (h *LeaderboardHub) Broadcast(payload []byte) {
+	frame := []byte(fmt.Sprintf("data: %s\n\n", payload))
 	select {
-	case h.broadcast <- payload:
+	case h.broadcast <- hubMessage{frame: frame, snapshot: true}:
 	default: // if buffer full, overwrite cache directly
 		h.mu.Lock()
-		h.cache = payload
+		h.cache = frame
 		h.mu.Unlock()
 	}
 }
 
-func (h *LeaderboardHub) AddClient(ctx context.Context) (<-chan []byte, func()) {
-	clientCh := make(chan []byte, 1)
+// Publish sends a named event to every connected client. Unlike snapshots,
+// events are never dropped at the hub while it is open.
+func (h *LeaderboardHub) Publish(event string, payload []byte) {
+	frame := []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+	select {
+	case h.broadcast <- hubMessage{frame: frame}:
+	case <-h.done:
+	}
+}
+
+// AddClient registers a client and sends it the last snapshot, if any. It
+// reports whether there was one to send.
+func (h *LeaderboardHub) AddClient() (chan []byte, bool) {
+	clientCh := make(chan []byte, 4)
 
 	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.clients[clientCh] = struct{}{}
 	if len(h.cache) > 0 {
 		clientCh <- h.cache // send last snapshot immediately
+		return clientCh, true
 	}
-	h.mu.Unlock()
+	return clientCh, false
+}
+
+// RemoveClient unregisters a client and returns how many are left.
+func (h *LeaderboardHub) RemoveClient(clientCh chan []byte) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[clientCh]; ok {
+		delete(h.clients, clientCh)
+		close(clientCh)
+	}
+	return len(h.clients)
+}
+
+// leaderboardHub returns the hub streaming the event's leaderboard, or nil
+// if nobody is watching it.
+func (h *Handler) leaderboardHub(eventID int) *LeaderboardHub {
+	h.hubsMu.Lock()
+	defer h.hubsMu.Unlock()
+	return h.hubs[eventID]
+}
+
+// subscribeLeaderboard adds a client to the event's hub, starting the hub if
+// needed. The returned function removes the client again, and stops the hub
+// once its last client has gone. primed is false if the client has not been
+// sent a snapshot yet.
+func (h *Handler) subscribeLeaderboard(eventID int) (clientCh <-chan []byte, unsubscribe func(), primed bool) {
+	h.hubsMu.Lock()
+	defer h.hubsMu.Unlock()
+
+	hub, ok := h.hubs[eventID]
+	if !ok {
+		hub = NewLeaderboardHub()
+		h.hubs[eventID] = hub
+	}
+	ch, primed := hub.AddClient()
 
 	var once sync.Once
-	cancelFn := func() {
+	unsubscribe = func() {
 		once.Do(func() {
-			h.mu.Lock()
-			delete(h.clients, clientCh)
-			close(clientCh)
-			h.mu.Unlock()
+			h.hubsMu.Lock()
+			defer h.hubsMu.Unlock()
+			if hub.RemoveClient(ch) == 0 && h.hubs[eventID] == hub {
+				delete(h.hubs, eventID)
+				hub.Close()
+			}
 		})
 	}
+	return ch, unsubscribe, primed
+}
 
-	// Auto-remove client when ctx closes
-	go func() {
-		<-ctx.Done()
-		cancelFn()
-	}()
-
-	return clientCh, cancelFn
+// closeLeaderboardHub stops the event's hub, disconnecting its clients.
+func (h *Handler) closeLeaderboardHub(eventID int) {
+	h.hubsMu.Lock()
+	defer h.hubsMu.Unlock()
+	if hub, ok := h.hubs[eventID]; ok {
+		delete(h.hubs, eventID)
+		hub.Close()
+	}
 }
 
 //
@@ -117,8 +249,9 @@ func (h *Handler) LeaderboardStream(c *gin.Context) {
 	c.Writer.Header().Set("Connection", "keep-alive")
 
 	ctx := c.Request.Context()
-	clientCh, cancel := h.LeaderboardHub.AddClient(ctx)
-	defer cancel()
+	eventID := currentEventID(c)
+	clientCh, unsubscribe, primed := h.subscribeLeaderboard(eventID)
+	defer unsubscribe()
 
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
@@ -128,6 +261,13 @@ func (h *Handler) LeaderboardStream(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 
+	// The first client of a hub has nothing cached to catch up on
+	if !primed {
+		if err := h.BroadcastLeaderboard(ctx, eventID); err != nil {
+			log.Printf("leaderboard broadcast for event %d failed: %v", eventID, err)
+		}
+	}
+
 	// Keepalive pings every 15s
 	ticker := time.NewTicker(15 * time.Second)
 	defer ticker.Stop()
@@ -139,11 +279,11 @@ func (h *Handler) LeaderboardStream(c *gin.Context) {
 		case <-ticker.C:
 			fmt.Fprintf(c.Writer, ": keepalive\n\n")
 			flusher.Flush()
-		case payload, ok := <-clientCh:
+		case frame, ok := <-clientCh:
 			if !ok {
 				return
 			}
-			fmt.Fprintf(c.Writer, "data: %s\n\n", payload)
+			c.Writer.Write(frame)
 			flusher.Flush()
 		}
 	}
@@ -153,18 +293,40 @@ func (h *Handler) LeaderboardStream(c *gin.Context) {
 // ==== Broadcast Builder ====
 //
 
-func (h *Handler) BroadcastLeaderboard(ctx context.Context) error {
-	totalClues, groups, err := h.groupService.GetLeaderboardData(ctx)
+// BroadcastLeaderboard sends a fresh snapshot to everyone watching the
+// event's leaderboard. Nothing is built when nobody is watching.
+func (h *Handler) BroadcastLeaderboard(ctx context.Context, eventID int) error {
+	hub := h.leaderboardHub(eventID)
+	if hub == nil {
+		return nil
+	}
+
+	pathwayTotals, groups, err := h.groupService.GetLeaderboardData(ctx, eventID)
 	if err != nil {
 		return fmt.Errorf("get leaderboard data: %w", err)
 	}
 
-	settings, err := h.gameService.GetGameStatus(ctx)
+	settings, err := h.gameService.GetGameStatus(ctx, eventID)
 	var startTime *time.Time
-	if err == nil && settings.StartTime != nil {
+	var game GameState
+	if err == nil {
 		startTime = settings.StartTime
+		game = newGameState(settings)
 	}
 
+	var pauses []models.GamePause
+	if startTime != nil {
+		if pauses, err = h.gameService.GetPauses(ctx, eventID); err != nil {
+			return fmt.Errorf("get pauses: %w", err)
+		}
+	}
+
+	timelines, err := h.buildTimelines(ctx, eventID, startTime, pauses)
+	if err != nil {
+		return fmt.Errorf("build timelines: %w", err)
+	}
+
+	now := time.Now()
 	var out []LeaderboardEntry
 	rank := 1
 	for _, group := range groups {
@@ -174,20 +336,41 @@ func (h *Handler) BroadcastLeaderboard(ctx context.Context) error {
 			Name:           group.Name,
 			Pathway:        group.Pathway,
 			CurrentClueIdx: group.CurrentClueIdx,
+			TotalClues:     1,
 			Completed:      group.Completed,
 		}
+		if total, ok := pathwayTotals[group.Pathway]; ok {
+			entry.TotalClues = total
+		}
+
+		// Add total_time if completed, excluding time spent paused
+		penalty := time.Duration(group.PenaltySeconds) * time.Second
+		if penalty > 0 {
+			formatted := formatDuration(penalty)
+			entry.PenaltyTime = &formatted
+		}
 
-		// Add total_time if completed
 		if group.Completed && group.EndTime != nil && startTime != nil {
-			duration := group.EndTime.Sub(*startTime)
-			totalSeconds := int(duration.Seconds())
-			hh := totalSeconds / 3600
-			mm := (totalSeconds % 3600) / 60
-			ss := totalSeconds % 60
-			formatted := fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss)
+			played := group.EndTime.Sub(*startTime) - pausedBetween(pauses, *startTime, *group.EndTime)
+			formatted := formatDuration(played + penalty)
 			entry.TotalTime = &formatted
 		}
 
+		// Per-clue splits and the time spent on the current clue so far
+		if startTime != nil {
+			currentStartedAt := *startTime
+			if tl, ok := timelines[group.ID]; ok {
+				entry.Splits = tl.Splits
+				currentStartedAt = tl.CurrentStartedAt
+			}
+			if !group.Completed {
+				startedAt := currentStartedAt.UTC().Format(time.RFC3339)
+				elapsed := formatDuration(now.Sub(currentStartedAt) - pausedBetween(pauses, currentStartedAt, now))
+				entry.CurrentClueStartedAt = &startedAt
+				entry.CurrentClueTime = &elapsed
+			}
+		}
+
 		// Assign medal badges for top 3
 		switch rank {
 		case 1:
@@ -210,14 +393,39 @@ func (h *Handler) BroadcastLeaderboard(ctx context.Context) error {
 	}
 
 	payload := LeaderboardPayload{
-		Groups:      out,
-		TotalClues:  totalClues,
-		TotalGroups: len(groups),
-		Completed:   completed,
-		InProgress:  len(groups) - completed,
+		Groups:        out,
+		PathwayTotals: pathwayTotals,
+		TotalGroups:   len(groups),
+		Completed:     completed,
+		InProgress:    len(groups) - completed,
+		Game:          game,
 	}
 
 	jsonBytes, _ := json.Marshal(payload)
-	h.LeaderboardHub.Broadcast(jsonBytes)
+	hub.Broadcast(jsonBytes)
 	return nil
 }
+
+// Lifecycle events published over the leaderboard stream.
+const (
+	EventGameStarted = "game_started"
+	EventGameEnded   = "game_ended"
+	EventGamePaused  = "game_paused"
+	EventGameResumed = "game_resumed"
+)
+
+// BroadcastGameEvent publishes a lifecycle event with the event's current
+// game state and refreshes its leaderboard snapshot.
+func (h *Handler) BroadcastGameEvent(ctx context.Context, eventID int, event string) error {
+	settings, err := h.gameService.GetGameStatus(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("get game status: %w", err)
+	}
+
+	if hub := h.leaderboardHub(eventID); hub != nil {
+		jsonBytes, _ := json.Marshal(newGameState(settings))
+		hub.Publish(event, jsonBytes)
+	}
+
+	return h.BroadcastLeaderboard(ctx, eventID)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	pathwayNamePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	pathwayColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+type pathwayRequest struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Active      *bool  `json:"active"`
+	TotalClues  *int   `json:"total_clues"`
+
+	ShuffleClues bool `json:"shuffle_clues"`
+	PinFirst     bool `json:"pin_first"`
+	PinLast      bool `json:"pin_last"`
+
+	// Mode is "linear" (the default) or "graph".
+	Mode string `json:"mode"`
+}
+
+func (r *pathwayRequest) clueOrder() models.ClueOrder {
+	return models.ClueOrder{Shuffle: r.ShuffleClues, PinFirst: r.PinFirst, PinLast: r.PinLast}
+}
+
+// normalize trims the request and fills in defaults. It returns a
+// user-facing message when the request is invalid.
+func (r *pathwayRequest) normalize() string {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	r.Color = strings.TrimSpace(r.Color)
+	r.Description = strings.TrimSpace(r.Description)
+
+	if r.Name == "" {
+		return "Pathway name is required"
+	}
+	if !pathwayNamePattern.MatchString(r.Name) {
+		return "Pathway name may only contain lowercase letters, digits, '-' and '_'"
+	}
+	if r.Color == "" {
+		r.Color = "#6b7280"
+	}
+	if !pathwayColorPattern.MatchString(r.Color) {
+		return "Color must be a hex value like #ef4444"
+	}
+	if r.TotalClues != nil && *r.TotalClues <= 0 {
+		return "Total clues must be greater than 0"
+	}
+	if (r.PinFirst || r.PinLast) && !r.ShuffleClues {
+		return "Clues can only be pinned on a shuffled pathway"
+	}
+	r.Mode = strings.ToLower(strings.TrimSpace(r.Mode))
+	if r.Mode == "" {
+		r.Mode = models.PathwayModeLinear
+	}
+	if r.Mode != models.PathwayModeLinear && r.Mode != models.PathwayModeGraph {
+		return "Mode must be 'linear' or 'graph'"
+	}
+	if r.Mode == models.PathwayModeGraph && r.ShuffleClues {
+		return "Graph pathways cannot shuffle their clues"
+	}
+	if r.Active == nil {
+		active := true
+		r.Active = &active
+	}
+	return ""
+}
+
+// validatePathway writes a 400 response and returns false when pathway is not
+// a configured pathway (or not an active one, when activeOnly is set).
+func (h *Handler) validatePathway(c *gin.Context, pathway string, activeOnly bool) bool {
+	err := h.pathwayService.ValidatePathway(c.Request.Context(), currentEventID(c), pathway, activeOnly)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, services.ErrInvalidPathway) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate pathway"})
+		return false
+	}
+
+	names, err := h.pathwayService.GetActivePathwayNames(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": fmt.Sprintf("Invalid pathway. Must be one of: %s", strings.Join(names, ", ")),
+	})
+	return false
+}
+
+func (h *Handler) GetPathways(c *gin.Context) {
+	pathways, err := h.pathwayService.GetAllPathways(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pathways": pathways})
+}
+
+func (h *Handler) AddPathway(c *gin.Context) {
+	var request pathwayRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if msg := request.normalize(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	err := h.pathwayService.AddPathway(c.Request.Context(), currentEventID(c), request.Name, request.Color, request.Description, *request.Active, request.TotalClues, request.clueOrder(), request.Mode)
+	if err != nil {
+		if errors.Is(err, services.ErrPathwayExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add pathway"})
+		return
+	}
+
+	auditAfter(c, request)
+	c.JSON(http.StatusCreated, gin.H{"message": "Pathway added successfully!"})
+}
+
+func (h *Handler) UpdatePathway(c *gin.Context) {
+	pathwayID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || pathwayID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+		return
+	}
+
+	var request pathwayRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if msg := request.normalize(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	auditBefore(c, h.auditPathway(c, pathwayID))
+	err = h.pathwayService.UpdatePathway(c.Request.Context(), currentEventID(c), pathwayID, request.Name, request.Color, request.Description, *request.Active, request.TotalClues, request.clueOrder(), request.Mode)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPathwayNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pathway not found"})
+		case errors.Is(err, services.ErrPathwayExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pathway"})
+		}
+		return
+	}
+
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
+		c.Header("X-Warning", "Leaderboard broadcast failed")
+	}
+
+	auditAfter(c, h.auditPathway(c, pathwayID))
+	c.JSON(http.StatusOK, gin.H{"message": "Pathway updated successfully!"})
+}
+
+func (h *Handler) DeletePathway(c *gin.Context) {
+	pathwayID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || pathwayID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+		return
+	}
+
+	auditBefore(c, h.auditPathway(c, pathwayID))
+	err = h.pathwayService.DeletePathway(c.Request.Context(), currentEventID(c), pathwayID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPathwayNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pathway not found"})
+		case errors.Is(err, services.ErrPathwayInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": "Pathway still has groups or clues; deactivate it instead"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete pathway"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pathway deleted successfully!"})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,10 +15,13 @@ func (h *Handler) GetQRData(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Get filter parameter (optional)
-	pathway := c.Query("pathway")
+	pathway := strings.ToLower(strings.TrimSpace(c.Query("pathway")))
+	if pathway != "" && !h.validatePathway(c, pathway, false) {
+		return
+	}
 
 	// Get all clues
-	allClues, err := h.clueService.GetAllClues(ctx)
+	allClues, err := h.clueService.GetAllClues(ctx, currentEventID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clues"})
 		return
@@ -36,6 +40,8 @@ func (h *Handler) GetQRData(c *gin.Context) {
 			"index":   clue.Index,
 			"content": clue.Content,
 			"qrcode":  clue.QRCode,
+			"label":   clue.Checkpoint.Label,
+			"notes":   clue.Checkpoint.PlacementNotes,
 		})
 	}
 
@@ -43,3 +49,27 @@ func (h *Handler) GetQRData(c *gin.Context) {
 		"clues": filteredClues,
 	})
 }
+
+// SignQRCodes replaces the code of every QR clue in the event with a signed
+// one. Printed codes must be reprinted afterwards.
+func (h *Handler) SignQRCodes(c *gin.Context) {
+	n, err := h.clueService.SignQRCodes(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign QR codes"})
+		return
+	}
+	auditAfter(c, gin.H{"count": n})
+	c.JSON(http.StatusOK, gin.H{"message": "QR codes signed successfully!", "count": n})
+}
+
+// RotateQRSecret replaces the event's signing secret and re-signs its signed
+// codes, so codes printed before stop working.
+func (h *Handler) RotateQRSecret(c *gin.Context) {
+	n, err := h.clueService.RotateQRSecret(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate QR secret"})
+		return
+	}
+	auditAfter(c, gin.H{"count": n})
+	c.JSON(http.StatusOK, gin.H{"message": "QR secret rotated successfully!", "count": n})
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	qrFormatPNG = "png"
+	qrFormatSVG = "svg"
+)
+
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// qrImageOptions controls how a QR code is drawn. Size is the width of the
+// image in pixels and Margin the quiet zone around the code in modules.
+type qrImageOptions struct {
+	Size   int
+	Level  qrcode.RecoveryLevel
+	Margin int
+}
+
+// parseQRImageOptions reads the size, level and margin query parameters,
+// writing a 400 response and returning false when one is invalid.
+func parseQRImageOptions(c *gin.Context) (qrImageOptions, bool) {
+	opts := qrImageOptions{Size: 256, Level: qrcode.Medium, Margin: 4}
+
+	if raw := c.Query("size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 64 || size > 2048 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Size must be between 64 and 2048 pixels"})
+			return opts, false
+		}
+		opts.Size = size
+	}
+	if raw := c.Query("level"); raw != "" {
+		level, ok := qrLevels[strings.ToUpper(raw)]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Level must be one of: L, M, Q, H"})
+			return opts, false
+		}
+		opts.Level = level
+	}
+	if raw := c.Query("margin"); raw != "" {
+		margin, err := strconv.Atoi(raw)
+		if err != nil || margin < 0 || margin > 16 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Margin must be between 0 and 16 modules"})
+			return opts, false
+		}
+		opts.Margin = margin
+	}
+	return opts, true
+}
+
+// qrModules encodes content and returns its modules surrounded by the
+// requested margin, true for dark.
+func qrModules(content string, opts qrImageOptions) ([][]bool, error) {
+	code, err := qrcode.New(content, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	n := len(bitmap) + 2*opts.Margin
+	modules := make([][]bool, n)
+	for y := range modules {
+		modules[y] = make([]bool, n)
+	}
+	for y, row := range bitmap {
+		copy(modules[y+opts.Margin][opts.Margin:], row)
+	}
+	return modules, nil
+}
+
+// renderQRPNG draws the code with the largest whole number of pixels per
+// module that fits the requested size, centred on a white background.
+func renderQRPNG(content string, opts qrImageOptions) ([]byte, error) {
+	modules, err := qrModules(content, opts)
+	if err != nil {
+		return nil, err
+	}
+	scale := max(opts.Size/len(modules), 1)
+	size := max(opts.Size, scale*len(modules))
+	offset := (size - scale*len(modules)) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetColorIndex(offset+x*scale+px, offset+y*scale+py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRSVG draws the code with one unit per module, so it scales to any
+// size without blurring. Runs of dark modules share a path segment.
+func renderQRSVG(content string, opts qrImageOptions) ([]byte, error) {
+	modules, err := qrModules(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, len(modules), len(modules))
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`, path.String())
+	return buf.Bytes(), nil
+}
+
+func renderQRImage(content, format string, opts qrImageOptions) ([]byte, error) {
+	if format == qrFormatSVG {
+		return renderQRSVG(content, opts)
+	}
+	return renderQRPNG(content, opts)
+}
+
+func qrContentType(format string) string {
+	if format == qrFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// splitQRFile splits a file name such as "12.png" into its base and format.
+func splitQRFile(file string) (string, string, bool) {
+	for _, format := range []string{qrFormatPNG, qrFormatSVG} {
+		if base, ok := strings.CutSuffix(file, "."+format); ok {
+			return base, format, true
+		}
+	}
+	return "", "", false
+}
+
+// GetQRImage renders the QR code of a clue as /api/qr/<clue id>.png or .svg.
+func (h *Handler) GetQRImage(c *gin.Context) {
+	base, format, ok := splitQRFile(c.Param("file"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	clueID, err := strconv.Atoi(base)
+	if err != nil || clueID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clue ID"})
+		return
+	}
+	opts, ok := parseQRImageOptions(c)
+	if !ok {
+		return
+	}
+
+	clue, err := h.clueService.GetClueByID(c.Request.Context(), currentEventID(c), clueID)
+	if err != nil {
+		if errors.Is(err, services.ErrClueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Clue not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clue"})
+		return
+	}
+	if clue.Answer.Type != models.AnswerTypeQR || clue.QRCode == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Clue has no QR code"})
+		return
+	}
+
+	data, err := renderQRImage(clue.QRCode, format, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, qrContentType(format), data)
+}
+
+// GetQRArchive downloads the QR codes of a pathway as
+// /api/qr/pathway/<name>.zip, one image per clue named after its index. The
+// format query parameter picks png (the default) or svg images.
+func (h *Handler) GetQRArchive(c *gin.Context) {
+	pathway, ok := strings.CutSuffix(c.Param("file"), ".zip")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	pathway = strings.ToLower(strings.TrimSpace(pathway))
+	if !h.validatePathway(c, pathway, false) {
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", qrFormatPNG))
+	if format != qrFormatPNG && format != qrFormatSVG {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be png or svg"})
+		return
+	}
+	opts, ok := parseQRImageOptions(c)
+	if !ok {
+		return
+	}
+
+	clues, err := h.clueService.GetAllClues(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clues"})
+		return
+	}
+
+	// Render everything before writing, so a failure can still be reported
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, clue := range clues {
+		if clue.Pathway != pathway || clue.Answer.Type != models.AnswerTypeQR || clue.QRCode == "" {
+			continue
+		}
+		data, err := renderQRImage(clue.QRCode, format, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+			return
+		}
+		w, err := zw.Create(fmt.Sprintf("%s_%03d.%s", pathway, clue.Index, format))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build archive"})
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build archive"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-qr.zip"`, pathway))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
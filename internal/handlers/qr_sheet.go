@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"cyberhunt/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+)
+
+// Checkpoint sheet layout in millimetres. Each page holds a grid of cards,
+// one per clue.
+const (
+	sheetMargin  = 10.0
+	sheetHeader  = 12.0
+	sheetGap     = 6.0
+	sheetColumns = 2
+	sheetRows    = 3
+	cardBar      = 8.0
+	cardText     = 18.0
+)
+
+var sheetPapers = map[string]string{
+	"a4":     "A4",
+	"letter": "Letter",
+}
+
+// sheetColor parses a pathway colour such as #ef4444, falling back to grey.
+func sheetColor(hex string) (int, int, int) {
+	if len(hex) == 7 && hex[0] == '#' {
+		if v, err := strconv.ParseUint(hex[1:], 16, 32); err == nil {
+			return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+		}
+	}
+	return 107, 114, 128
+}
+
+// GetQRSheet lays out the event's QR codes as printable checkpoint cards,
+// optionally for one pathway. The paper query parameter picks a4 (the
+// default) or letter pages.
+func (h *Handler) GetQRSheet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pathway := strings.ToLower(strings.TrimSpace(c.Query("pathway")))
+	if pathway != "" && !h.validatePathway(c, pathway, false) {
+		return
+	}
+	paper, ok := sheetPapers[strings.ToLower(c.DefaultQuery("paper", "a4"))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Paper must be a4 or letter"})
+		return
+	}
+
+	pathways, err := h.pathwayService.GetAllPathways(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	colors := make(map[string]string, len(pathways))
+	for _, p := range pathways {
+		colors[p.Name] = p.Color
+	}
+
+	allClues, err := h.clueService.GetAllClues(ctx, currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clues"})
+		return
+	}
+	var clues []*models.Clue
+	for _, clue := range allClues {
+		if (pathway == "" || clue.Pathway == pathway) && clue.Answer.Type == models.AnswerTypeQR && clue.QRCode != "" {
+			clues = append(clues, clue)
+		}
+	}
+
+	pdf := fpdf.New("P", "mm", paper, "")
+	pdf.SetTitle("Checkpoint sheet", true)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageW, pageH := pdf.GetPageSize()
+	cardW := (pageW - 2*sheetMargin - (sheetColumns-1)*sheetGap) / sheetColumns
+	cardH := (pageH - 2*sheetMargin - sheetHeader - (sheetRows-1)*sheetGap) / sheetRows
+	qrSize := min(cardW, cardH-cardBar-cardText) - 4
+	perPage := sheetColumns * sheetRows
+
+	title := "Checkpoint sheet"
+	if pathway != "" {
+		title += " - " + pathway
+	}
+	if len(clues) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.Text(sheetMargin, sheetMargin+6, tr(title))
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.Text(sheetMargin, sheetMargin+sheetHeader+6, "No QR codes to print.")
+	}
+
+	for i, clue := range clues {
+		if i%perPage == 0 {
+			pdf.AddPage()
+			pdf.SetTextColor(0, 0, 0)
+			pdf.SetFont("Helvetica", "B", 14)
+			pdf.Text(sheetMargin, sheetMargin+6, tr(title))
+			pdf.SetFont("Helvetica", "", 9)
+			page := fmt.Sprintf("Page %d of %d", i/perPage+1, (len(clues)+perPage-1)/perPage)
+			pdf.Text(pageW-sheetMargin-pdf.GetStringWidth(page), sheetMargin+6, page)
+		}
+
+		slot := i % perPage
+		x := sheetMargin + float64(slot%sheetColumns)*(cardW+sheetGap)
+		y := sheetMargin + sheetHeader + float64(slot/sheetColumns)*(cardH+sheetGap)
+		r, g, b := sheetColor(colors[clue.Pathway])
+
+		// Card outline with a bar in the pathway colour
+		pdf.SetDrawColor(r, g, b)
+		pdf.SetLineWidth(0.6)
+		pdf.Rect(x, y, cardW, cardH, "D")
+		pdf.SetFillColor(r, g, b)
+		pdf.Rect(x, y, cardW, cardBar, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetXY(x+3, y)
+		pdf.CellFormat(cardW-6, cardBar, tr(strings.ToUpper(clue.Pathway)), "", 0, "L", false, 0, "")
+		pdf.SetXY(x+3, y)
+		pdf.CellFormat(cardW-6, cardBar, fmt.Sprintf("#%d", clue.Index), "", 0, "R", false, 0, "")
+
+		png, err := renderQRPNG(clue.QRCode, qrImageOptions{Size: 512, Level: qrLevels["M"], Margin: 2})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+			return
+		}
+		name := fmt.Sprintf("clue-%d", clue.ID)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+		pdf.ImageOptions(name, x+(cardW-qrSize)/2, y+cardBar+2, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		label := clue.Checkpoint.Label
+		if label == "" {
+			label = fmt.Sprintf("Checkpoint %d", clue.Index)
+		}
+		textY := y + cardBar + qrSize + 4
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetXY(x+3, textY)
+		pdf.CellFormat(cardW-6, 6, tr(label), "", 0, "C", false, 0, "")
+
+		if notes := clue.Checkpoint.PlacementNotes; notes != "" {
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.SetTextColor(80, 80, 80)
+			lines := pdf.SplitText(tr(notes), cardW-6)
+			if len(lines) > 3 {
+				lines = append(lines[:2], strings.TrimRight(lines[2], " ")+"...")
+			}
+			for j, line := range lines {
+				pdf.SetXY(x+3, textY+6+float64(j)*3.5)
+				pdf.CellFormat(cardW-6, 3.5, line, "", 0, "C", false, 0, "")
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build PDF"})
+		return
+	}
+
+	filename := "checkpoints.pdf"
+	if pathway != "" {
+		filename = pathway + "-checkpoints.pdf"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
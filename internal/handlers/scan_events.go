@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"cyberhunt/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// parsePagination reads the 1-based page and page_size query parameters.
+func parsePagination(c *gin.Context) (page, pageSize int, ok bool) {
+	page, pageSize = 1, defaultPageSize
+	var err error
+	if v := c.Query("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+			return 0, 0, false
+		}
+	}
+	if v := c.Query("page_size"); v != "" {
+		if pageSize, err = strconv.Atoi(v); err != nil || pageSize < 1 || pageSize > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page_size must be between 1 and 500"})
+			return 0, 0, false
+		}
+	}
+	return page, pageSize, true
+}
+
+// parseTimeQuery reads an optional RFC3339 timestamp query parameter.
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + ", expected RFC3339"})
+		return nil, false
+	}
+	return &t, true
+}
+
+// parseIDQuery reads an optional positive integer query parameter.
+func parseIDQuery(c *gin.Context, name string) (int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) GetScanEvents(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	filter := services.ScanEventFilter{
+		EventID:         currentEventID(c),
+		Result:          c.Query("result"),
+		OutsideGeofence: c.Query("outside_geofence") == "true",
+		Limit:           pageSize,
+		Offset:          (page - 1) * pageSize,
+	}
+	if filter.GroupID, ok = parseIDQuery(c, "group_id"); !ok {
+		return
+	}
+	if filter.ClueID, ok = parseIDQuery(c, "clue_id"); !ok {
+		return
+	}
+	if filter.Since, ok = parseTimeQuery(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = parseTimeQuery(c, "until"); !ok {
+		return
+	}
+
+	events, total, err := h.scanService.ListScanEvents(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scan events"})
+		return
+	}
+
+	out := make([]gin.H, 0, len(events))
+	for _, e := range events {
+		out = append(out, gin.H{
+			"id":             e.ID,
+			"group_id":       e.GroupID,
+			"group_name":     e.GroupName,
+			"pathway":        e.Pathway,
+			"clue_id":        e.ClueID,
+			"clue_idx":       e.ClueIdx,
+			"submitted_code": e.SubmittedCode,
+			"result":         e.Result,
+			"client_ip":      e.ClientIP,
+			"user_agent":     e.UserAgent,
+			"created_at":     e.CreatedAt.UTC().Format(time.RFC3339Nano),
+
+			"latitude":         e.Latitude,
+			"longitude":        e.Longitude,
+			"accuracy_meters":  e.AccuracyMeters,
+			"distance_meters":  e.DistanceMeters,
+			"outside_geofence": e.OutsideGeofence,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":    out,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"cyberhunt/internal/services"
+	"errors"
+	"log"
+	"time"
+)
+
+// RunScheduler starts and ends the game of every event that is not archived
+// according to the schedule stored in game_settings until ctx is cancelled. The schedule is re-read from the
+// database on every tick, so changes and restarts are picked up without any
+// in-memory state.
+func (h *Handler) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.schedulerTick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Handler) schedulerTick(ctx context.Context) {
+	events, err := h.eventService.ListEvents(ctx, false)
+	if err != nil {
+		log.Printf("scheduler: %v", err)
+		return
+	}
+	for _, event := range events {
+		h.scheduleEvent(ctx, event.ID)
+	}
+}
+
+func (h *Handler) scheduleEvent(ctx context.Context, eventID int) {
+	settings, err := h.gameService.GetGameStatus(ctx, eventID)
+	if err != nil {
+		log.Printf("scheduler: event %d: %v", eventID, err)
+		return
+	}
+	now := time.Now()
+
+	if !settings.GameStarted && settings.ScheduledStart != nil && !now.Before(*settings.ScheduledStart) {
+		// The setup was checked when the start was scheduled; a game that
+		// is due still starts, but what changed since is logged
+		if report, err := h.gameService.Preflight(ctx, eventID); err == nil {
+			for _, issue := range report.Issues {
+				log.Printf("scheduler: event %d: preflight %s: %s", eventID, issue.Severity, issue.Message)
+			}
+		}
+		err := h.gameService.StartGame(ctx, eventID)
+		switch {
+		case err == nil:
+			log.Printf("scheduler: event %d: game started as scheduled for %s", eventID, settings.ScheduledStart.UTC().Format(time.RFC3339))
+			if err := h.BroadcastGameEvent(ctx, eventID, EventGameStarted); err != nil {
+				log.Printf("scheduler: broadcast failed: %v", err)
+			}
+		case errors.Is(err, services.ErrGameAlreadyStarted):
+			// Started manually in the meantime
+		default:
+			log.Printf("scheduler: event %d: failed to start game: %v", eventID, err)
+		}
+		return
+	}
+
+	if endsAt := settings.EndsAt(); settings.GameStarted && !settings.GameEnded && endsAt != nil && !now.Before(*endsAt) {
+		err := h.gameService.EndGame(ctx, eventID)
+		switch {
+		case err == nil:
+			log.Printf("scheduler: event %d: game ended after its maximum duration", eventID)
+			if err := h.BroadcastGameEvent(ctx, eventID, EventGameEnded); err != nil {
+				log.Printf("scheduler: broadcast failed: %v", err)
+			}
+		case errors.Is(err, services.ErrGameAlreadyEnded):
+			// Ended manually in the meantime
+		default:
+			log.Printf("scheduler: event %d: failed to end game: %v", eventID, err)
+		}
+	}
+}
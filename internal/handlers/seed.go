@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +18,20 @@ func (h *Handler) SeedPage(c *gin.Context) {
 }
 
 func (h *Handler) SeedGroups(c *gin.Context) {
-	pathways := []string{"red", "blue", "yellow", "green"}
-	groupsPerPathway := 12 // 50 / 4 = 12.5, so 12 or 13. Let's do 13 for one pathway.
+	pathways, err := h.pathwayService.GetActivePathwayNames(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	if len(pathways) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No active pathways to seed"})
+		return
+	}
+	groupsPerPathway := 12
 
 	pathwayCount := make(map[string]int)
-	for i := 0; i < 4; i++ {
-		pathwayCount[pathways[i]] = groupsPerPathway
+	for _, pathway := range pathways {
+		pathwayCount[pathway] = groupsPerPathway
 	}
 
 	for pathway, count := range pathwayCount {
@@ -27,7 +39,7 @@ func (h *Handler) SeedGroups(c *gin.Context) {
 			name := fmt.Sprintf("Group_%s_%03d", pathway, i+1)
 			password := "test"
 
-			err := h.groupService.AddGroup(c.Request.Context(), name, pathway, password)
+			err := h.groupService.AddGroup(c.Request.Context(), currentEventID(c), name, pathway, password)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to seed groups"})
 				return
@@ -35,11 +47,20 @@ func (h *Handler) SeedGroups(c *gin.Context) {
 		}
 	}
 
+	auditAfter(c, gin.H{"pathways": pathways, "groups_per_pathway": groupsPerPathway})
 	c.JSON(http.StatusOK, gin.H{"message": "Groups seeded successfully!"})
 }
 
 func (h *Handler) SeedClues(c *gin.Context) {
-	pathways := []string{"red", "blue", "yellow", "green"}
+	pathways, err := h.pathwayService.GetActivePathwayNames(c.Request.Context(), currentEventID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pathways"})
+		return
+	}
+	if len(pathways) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No active pathways to seed"})
+		return
+	}
 	cluesPerPathway := 10
 
 	riddles := []string{
@@ -85,8 +106,12 @@ func (h *Handler) SeedClues(c *gin.Context) {
 		"What has a handle but no door?",
 	}
 
+	if clues, err := h.clueService.GetAllClues(c.Request.Context(), currentEventID(c)); err == nil {
+		auditBefore(c, clues)
+	}
+
 	// Clear existing clues
-	err := h.clueService.ClearClues(c.Request.Context())
+	err = h.clueService.ClearClues(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear existing clues"})
 		return
@@ -94,10 +119,10 @@ func (h *Handler) SeedClues(c *gin.Context) {
 
 	for _, pathway := range pathways {
 		for i := 0; i < cluesPerPathway; i++ {
-			qrCode := fmt.Sprintf("%s_%03d", pathway, i)
 			content := riddles[rand.Intn(len(riddles))]
 
-			err := h.clueService.AddClue(c.Request.Context(), pathway, i, content, qrCode)
+			// Without a code the clue gets a signed one that cannot be guessed
+			err := h.clueService.AddClue(c.Request.Context(), currentEventID(c), pathway, i, content, "", models.ClueAnswer{}, nil, models.Checkpoint{})
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to insert clue %s_%03d: %v", pathway, i, err)})
 				return
@@ -105,12 +130,16 @@ func (h *Handler) SeedClues(c *gin.Context) {
 		}
 	}
 
+	auditAfter(c, gin.H{"pathways": pathways, "clues_per_pathway": cluesPerPathway})
 	c.JSON(http.StatusOK, gin.H{"message": "Clues seeded successfully!"})
 }
 
 func (h *Handler) UpdateTotalClues(c *gin.Context) {
+	// An empty pathway applies the total to every pathway; a null total
+	// reverts to deriving the length from the pathway's clues.
 	var request struct {
-		TotalClues int `json:"total_clues"`
+		Pathway    string `json:"pathway"`
+		TotalClues *int   `json:"total_clues"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -118,27 +147,43 @@ func (h *Handler) UpdateTotalClues(c *gin.Context) {
 		return
 	}
 
-	if request.TotalClues <= 0 {
+	if request.TotalClues != nil && *request.TotalClues <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Total clues must be greater than 0"})
 		return
 	}
 
-	err := h.adminService.UpdateTotalClues(c.Request.Context(), request.TotalClues)
+	pathway := strings.ToLower(strings.TrimSpace(request.Pathway))
+	if pathway != "" && !h.validatePathway(c, pathway, false) {
+		return
+	}
+
+	if pathways, err := h.pathwayService.GetAllPathways(c.Request.Context(), currentEventID(c)); err == nil {
+		totals := map[string]*int{}
+		for _, p := range pathways {
+			if pathway == "" || p.Name == pathway {
+				totals[p.Name] = p.TotalClues
+			}
+		}
+		auditBefore(c, totals)
+	}
+
+	err := h.pathwayService.SetTotalClues(c.Request.Context(), currentEventID(c), pathway, request.TotalClues)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update total clues"})
 		return
 	}
-	if err := h.BroadcastLeaderboard(c.Request.Context()); err != nil {
+	if err := h.BroadcastLeaderboard(c.Request.Context(), currentEventID(c)); err != nil {
 		// Log the error but don't fail the request since group was added successfully
 		c.Header("X-Warning", "Leaderboard broadcast failed")
 	}
 
+	auditAfter(c, gin.H{"pathway": pathway, "total_clues": request.TotalClues})
 	c.JSON(http.StatusOK, gin.H{"message": "Total clues updated successfully!"})
 }
 
 // Get all clues
 func (h *Handler) GetAllClues(c *gin.Context) {
-	clues, err := h.clueService.GetAllClues(c.Request.Context())
+	clues, err := h.clueService.GetAllClues(c.Request.Context(), currentEventID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clues"})
 		return
@@ -146,13 +191,82 @@ func (h *Handler) GetAllClues(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"clues": clues})
 }
 
+// clueAnswerRequest is how a clue is solved; clues are QR clues unless
+// answer_type says otherwise.
+type clueAnswerRequest struct {
+	AnswerType    string   `json:"answer_type"`
+	Answers       []string `json:"answers"`
+	AnswerPattern string   `json:"answer_pattern"`
+	Choices       []string `json:"choices"`
+	CorrectChoice int      `json:"correct_choice"`
+}
+
+func (r *clueAnswerRequest) answer() models.ClueAnswer {
+	return models.ClueAnswer{
+		Type:          r.AnswerType,
+		Answers:       r.Answers,
+		Pattern:       r.AnswerPattern,
+		Choices:       r.Choices,
+		CorrectChoice: r.CorrectChoice,
+	}
+}
+
+// clueGeofenceRequest ties a clue to a location. Latitude, longitude and
+// radius are set together or not at all.
+type clueGeofenceRequest struct {
+	Latitude       *float64 `json:"latitude"`
+	Longitude      *float64 `json:"longitude"`
+	RadiusMeters   *int     `json:"radius_meters"`
+	GeofenceReject bool     `json:"geofence_reject"`
+}
+
+// geofence returns the requested geofence, or a user-facing message when the
+// request is invalid.
+func (r *clueGeofenceRequest) geofence() (*models.Geofence, string) {
+	if r.Latitude == nil && r.Longitude == nil && r.RadiusMeters == nil {
+		return nil, ""
+	}
+	if r.Latitude == nil || r.Longitude == nil || r.RadiusMeters == nil {
+		return nil, "Latitude, longitude and radius must be set together"
+	}
+	if *r.Latitude < -90 || *r.Latitude > 90 || *r.Longitude < -180 || *r.Longitude > 180 {
+		return nil, "Latitude or longitude out of range"
+	}
+	if *r.RadiusMeters <= 0 {
+		return nil, "Radius must be greater than 0"
+	}
+	return &models.Geofence{
+		Latitude:     *r.Latitude,
+		Longitude:    *r.Longitude,
+		RadiusMeters: *r.RadiusMeters,
+		Reject:       r.GeofenceReject,
+	}, ""
+}
+
+// clueCheckpointRequest describes where a clue's code is posted, for
+// printed checkpoint sheets.
+type clueCheckpointRequest struct {
+	CheckpointLabel string `json:"checkpoint_label"`
+	PlacementNotes  string `json:"placement_notes"`
+}
+
+func (r *clueCheckpointRequest) checkpoint() models.Checkpoint {
+	return models.Checkpoint{
+		Label:          strings.TrimSpace(r.CheckpointLabel),
+		PlacementNotes: strings.TrimSpace(r.PlacementNotes),
+	}
+}
+
 // Add new clue
 func (h *Handler) AddClue(c *gin.Context) {
 	var request struct {
 		Pathway string `json:"pathway" binding:"required"`
 		Index   string `json:"index" binding:"required"`
 		Content string `json:"content" binding:"required"`
-		QRCode  string `json:"qrcode" binding:"required"`
+		QRCode  string `json:"qrcode"`
+		clueAnswerRequest
+		clueGeofenceRequest
+		clueCheckpointRequest
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -171,12 +285,28 @@ func (h *Handler) AddClue(c *gin.Context) {
 		return
 	}
 
-	err = h.clueService.AddClue(c.Request.Context(), request.Pathway, clueIndex, request.Content, request.QRCode)
+	pathway := strings.ToLower(strings.TrimSpace(request.Pathway))
+	if !h.validatePathway(c, pathway, false) {
+		return
+	}
+
+	fence, msg := request.geofence()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	err = h.clueService.AddClue(c.Request.Context(), currentEventID(c), pathway, clueIndex, request.Content, request.QRCode, request.answer(), fence, request.checkpoint())
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidClueAnswer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add clue: " + err.Error()})
 		return
 	}
 
+	auditAfter(c, request)
 	c.JSON(http.StatusOK, gin.H{"message": "Clue added successfully!"})
 }
 
@@ -198,7 +328,10 @@ func (h *Handler) UpdateClue(c *gin.Context) {
 		Pathway string `json:"pathway" binding:"required"`
 		Index   string `json:"index" binding:"required"`
 		Content string `json:"content" binding:"required"`
-		QRCode  string `json:"qrcode" binding:"required"`
+		QRCode  string `json:"qrcode"`
+		clueAnswerRequest
+		clueGeofenceRequest
+		clueCheckpointRequest
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -217,12 +350,34 @@ func (h *Handler) UpdateClue(c *gin.Context) {
 		return
 	}
 
-	err = h.clueService.UpdateClue(c.Request.Context(), clueID, request.Pathway, clueIndex, request.Content, request.QRCode)
+	pathway := strings.ToLower(strings.TrimSpace(request.Pathway))
+	if !h.validatePathway(c, pathway, false) {
+		return
+	}
+
+	fence, msg := request.geofence()
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if clue, err := h.clueService.GetClueByID(c.Request.Context(), currentEventID(c), clueID); err == nil {
+		auditBefore(c, clue)
+	}
+
+	err = h.clueService.UpdateClue(c.Request.Context(), currentEventID(c), clueID, pathway, clueIndex, request.Content, request.QRCode, request.answer(), fence, request.checkpoint())
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidClueAnswer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update clue: " + err.Error()})
 		return
 	}
 
+	if clue, err := h.clueService.GetClueByID(c.Request.Context(), currentEventID(c), clueID); err == nil {
+		auditAfter(c, clue)
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Clue updated successfully!"})
 }
 
@@ -240,7 +395,11 @@ func (h *Handler) DeleteClue(c *gin.Context) {
 		return
 	}
 
-	err := h.clueService.DeleteClue(c.Request.Context(), clueID)
+	if clue, err := h.clueService.GetClueByID(c.Request.Context(), currentEventID(c), clueID); err == nil {
+		auditBefore(c, clue)
+	}
+
+	err := h.clueService.DeleteClue(c.Request.Context(), currentEventID(c), clueID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete clue: " + err.Error()})
 		return
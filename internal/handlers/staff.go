@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/services"
+	"cyberhunt/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentAdmin returns the admin RequirePermission loaded for the request.
+func currentAdmin(c *gin.Context) *models.Admin {
+	admin, _ := c.Get("admin")
+	a, _ := admin.(*models.Admin)
+	return a
+}
+
+// RequirePermission lets the request through only if the logged-in admin's
+// role grants the permission. The role is read on every request so that
+// removing or demoting staff takes effect straight away. It must run after
+// AdminAuthMiddleware.
+func (h *Handler) RequirePermission(p models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, err := h.adminService.GetAdmin(c.Request.Context(), c.GetInt("adminID"))
+		if err != nil {
+			if errors.Is(err, services.ErrAdminNotFound) {
+				if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				} else {
+					c.Redirect(http.StatusFound, "/admin/login")
+					c.Abort()
+				}
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return
+		}
+		if !admin.Can(p) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Your role does not allow this"})
+			return
+		}
+
+		c.Set("admin", admin)
+		c.Next()
+	}
+}
+
+func staffResponse(a *models.Admin) gin.H {
+	return gin.H{"id": a.ID, "name": a.Name, "role": a.Role}
+}
+
+// GetCurrentAdmin reports who is logged in and what their role allows, so
+// the admin pages can hide what they cannot use.
+func (h *Handler) GetCurrentAdmin(c *gin.Context) {
+	admin := currentAdmin(c)
+	response := staffResponse(admin)
+	response["permissions"] = models.RolePermissions(admin.Role)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetStaff(c *gin.Context) {
+	admins, err := h.adminService.ListAdmins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staff"})
+		return
+	}
+
+	out := make([]gin.H, 0, len(admins))
+	for _, a := range admins {
+		out = append(out, staffResponse(a))
+	}
+	c.JSON(http.StatusOK, gin.H{"staff": out, "roles": models.AdminRoles})
+}
+
+// InviteStaff creates an admin account with a generated password, which is
+// only ever shown in the response.
+func (h *Handler) InviteStaff(c *gin.Context) {
+	var request struct {
+		Name string `json:"name" binding:"required"`
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	password := utils.GenerateSecurePassword(12)
+	admin, err := h.adminService.CreateAdmin(c.Request.Context(), name, password, request.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAdminRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be one of: " + strings.Join(models.AdminRoles, ", ")})
+		case errors.Is(err, services.ErrAdminExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite staff"})
+		}
+		return
+	}
+
+	auditTarget(c, admin.ID)
+	auditAfter(c, staffResponse(admin))
+	response := staffResponse(admin)
+	response["message"] = "Staff invited successfully!"
+	response["password"] = password
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateStaff changes the role of a member of staff.
+func (h *Handler) UpdateStaff(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if admin, err := h.adminService.GetAdmin(c.Request.Context(), id); err == nil {
+		auditBefore(c, staffResponse(admin))
+	}
+
+	if err := h.adminService.UpdateAdminRole(c.Request.Context(), id, request.Role); err != nil {
+		writeStaffError(c, err, "Failed to update staff")
+		return
+	}
+	if admin, err := h.adminService.GetAdmin(c.Request.Context(), id); err == nil {
+		auditAfter(c, staffResponse(admin))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Staff updated successfully!"})
+}
+
+// ResetStaffPassword gives a member of staff a new generated password.
+func (h *Handler) ResetStaffPassword(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+
+	password := utils.GenerateSecurePassword(12)
+	if err := h.adminService.ResetAdminPassword(c.Request.Context(), id, password); err != nil {
+		writeStaffError(c, err, "Failed to reset password")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully!", "password": password})
+}
+
+// RemoveStaff deletes an admin account. Owners cannot remove themselves.
+func (h *Handler) RemoveStaff(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return
+	}
+	if id == currentAdmin(c).ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own account"})
+		return
+	}
+
+	if admin, err := h.adminService.GetAdmin(c.Request.Context(), id); err == nil {
+		auditBefore(c, staffResponse(admin))
+	}
+
+	if err := h.adminService.DeleteAdmin(c.Request.Context(), id); err != nil {
+		writeStaffError(c, err, "Failed to remove staff")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Staff removed successfully!"})
+}
+
+func writeStaffError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAdminNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+	case errors.Is(err, services.ErrInvalidAdminRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be one of: " + strings.Join(models.AdminRoles, ", ")})
+	case errors.Is(err, services.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
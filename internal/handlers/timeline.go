@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SplitEntry struct {
+	ClueIdx  int    `json:"clue_idx"`
+	ClueID   *int   `json:"clue_id,omitempty"`
+	SolvedAt string `json:"solved_at"`
+	Seconds  int    `json:"seconds"`
+	Time     string `json:"time"`
+	Fastest  bool   `json:"fastest,omitempty"`
+}
+
+// groupTimeline is a group's solve history relative to the game start.
+type groupTimeline struct {
+	Splits           []SplitEntry
+	CurrentStartedAt time.Time
+}
+
+// formatDuration renders a duration as HH:MM:SS.
+func formatDuration(d time.Duration) string {
+	totalSeconds := int(d.Seconds())
+	if totalSeconds < 0 {
+		totalSeconds = 0
+	}
+	hh := totalSeconds / 3600
+	mm := (totalSeconds % 3600) / 60
+	ss := totalSeconds % 60
+	return fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss)
+}
+
+// pausedBetween returns how much of the interval from..to the game spent
+// paused. A pause in progress counts up to 'to'.
+func pausedBetween(pauses []models.GamePause, from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range pauses {
+		start, end := p.PausedAt, to
+		if p.ResumedAt != nil && p.ResumedAt.Before(end) {
+			end = *p.ResumedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// buildTimelines computes every group's split per solved clue from the
+// recorded solve times and flags the fastest split on each clue. Time the
+// game spent paused does not count towards a split. It returns nil when the
+// game has not started.
+func (h *Handler) buildTimelines(ctx context.Context, eventID int, startTime *time.Time, pauses []models.GamePause) (map[int]*groupTimeline, error) {
+	if startTime == nil {
+		return nil, nil
+	}
+
+	solves, err := h.scanService.GetSolveTimes(ctx, eventID, *startTime, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	timelines := make(map[int]*groupTimeline, len(solves))
+	fastest := make(map[int]int) // clue ID -> fastest split in seconds
+	for groupID, groupSolves := range solves {
+		tl := &groupTimeline{CurrentStartedAt: *startTime}
+		for _, sv := range groupSolves {
+			split := sv.SolvedAt.Sub(tl.CurrentStartedAt) - pausedBetween(pauses, tl.CurrentStartedAt, sv.SolvedAt)
+			entry := SplitEntry{
+				ClueIdx:  sv.ClueIdx,
+				ClueID:   sv.ClueID,
+				SolvedAt: sv.SolvedAt.UTC().Format(time.RFC3339),
+				Seconds:  int(split.Seconds()),
+				Time:     formatDuration(split),
+			}
+			tl.Splits = append(tl.Splits, entry)
+			tl.CurrentStartedAt = sv.SolvedAt
+
+			if sv.ClueID != nil {
+				if best, ok := fastest[*sv.ClueID]; !ok || entry.Seconds < best {
+					fastest[*sv.ClueID] = entry.Seconds
+				}
+			}
+		}
+		timelines[groupID] = tl
+	}
+
+	for _, tl := range timelines {
+		for i := range tl.Splits {
+			split := &tl.Splits[i]
+			if split.ClueID != nil && fastest[*split.ClueID] == split.Seconds {
+				split.Fastest = true
+			}
+		}
+	}
+
+	return timelines, nil
+}
+
+// GroupTimeline returns the logged-in group's split per solved clue and the
+// time spent on its current clue.
+func (h *Handler) GroupTimeline(c *gin.Context) {
+	groupID, _ := c.Get("groupID")
+	ctx := c.Request.Context()
+
+	group, err := h.groupService.GetGroupByID(ctx, groupID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get group"})
+		return
+	}
+
+	settings, err := h.gameService.GetGameStatus(ctx, group.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game status"})
+		return
+	}
+
+	response := gin.H{
+		"group":       group.Name,
+		"pathway":     group.Pathway,
+		"completed":   group.Completed,
+		"currentClue": group.CurrentClueIdx,
+		"splits":      []SplitEntry{},
+	}
+	if settings.StartTime == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	response["startTime"] = settings.StartTime.UTC().Format(time.RFC3339)
+
+	pauses, err := h.gameService.GetPauses(ctx, group.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game pauses"})
+		return
+	}
+
+	timelines, err := h.buildTimelines(ctx, group.EventID, settings.StartTime, pauses)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build timeline"})
+		return
+	}
+
+	tl, ok := timelines[group.ID]
+	if !ok {
+		tl = &groupTimeline{CurrentStartedAt: *settings.StartTime}
+	}
+	if tl.Splits != nil {
+		response["splits"] = tl.Splits
+	}
+
+	if !group.Completed {
+		now := time.Now()
+		elapsed := now.Sub(tl.CurrentStartedAt) - pausedBetween(pauses, tl.CurrentStartedAt, now)
+		response["currentClueStartedAt"] = tl.CurrentStartedAt.UTC().Format(time.RFC3339)
+		response["currentClueSeconds"] = int(elapsed.Seconds())
+		response["currentClueTime"] = formatDuration(elapsed)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
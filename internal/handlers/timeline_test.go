@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"cyberhunt/internal/models"
+	"testing"
+	"time"
+)
+
+func TestPausedBetween(t *testing.T) {
+	base := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	pause := func(from, to int) models.GamePause {
+		resumed := at(to)
+		return models.GamePause{PausedAt: at(from), ResumedAt: &resumed}
+	}
+	open := func(from int) models.GamePause {
+		return models.GamePause{PausedAt: at(from)}
+	}
+
+	// The interval runs from minute 10 to minute 60
+	tests := []struct {
+		name   string
+		pauses []models.GamePause
+		want   int
+	}{
+		{"no pauses", nil, 0},
+		{"inside", []models.GamePause{pause(20, 30)}, 10},
+		{"entirely before from", []models.GamePause{pause(0, 5)}, 0},
+		{"ending at from", []models.GamePause{pause(0, 10)}, 0},
+		{"entirely after to", []models.GamePause{pause(70, 80)}, 0},
+		{"straddling from", []models.GamePause{pause(5, 15)}, 5},
+		{"straddling to", []models.GamePause{pause(55, 70)}, 5},
+		{"covering the interval", []models.GamePause{pause(0, 90)}, 50},
+		{"open inside", []models.GamePause{open(40)}, 20},
+		{"open before from", []models.GamePause{open(0)}, 50},
+		{"open after to", []models.GamePause{open(70)}, 0},
+		{"several", []models.GamePause{pause(0, 12), pause(20, 25), pause(30, 40), open(58)}, 2 + 5 + 10 + 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pausedBetween(tt.pauses, at(10), at(60))
+			if want := time.Duration(tt.want) * time.Minute; got != want {
+				t.Errorf("pausedBetween = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "00:00:00"},
+		{-5 * time.Second, "00:00:00"},
+		{59*time.Second + 900*time.Millisecond, "00:00:59"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "01:02:03"},
+		{100 * time.Hour, "100:00:00"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,67 @@
 package models
 
 import (
+	"encoding/json"
+	"slices"
 	"time"
 )
 
+// Event is a single hunt. Settings, pathways, clues and groups all belong
+// to exactly one event.
+type Event struct {
+	ID        int
+	Name      string
+	Archived  bool
+	CreatedAt time.Time
+}
+
 type Group struct {
 	ID             int
+	EventID        int
 	Name           string
 	Pathway        string
 	CurrentClueIdx int
 	Completed      bool
 	EndTime        *time.Time
 	Password       string
+	PenaltySeconds int
+	LockedUntil    *time.Time
+	// CurrentClueID is the clue a group on a graph pathway is at, or nil
+	// while it is at the start.
+	CurrentClueID *int
+	Members       []string
+}
+
+// Pathway modes. Linear pathways are walked by index_num; graph pathways
+// follow the clue edges.
+const (
+	PathwayModeLinear = "linear"
+	PathwayModeGraph  = "graph"
+)
+
+type Pathway struct {
+	ID          int
+	Name        string
+	Color       string
+	Description string
+	Active      bool
+	// TotalClues is the explicitly configured length of the pathway, or nil
+	// when the length is derived from the number of clues on it.
+	TotalClues *int
+	// EffectiveTotalClues is the length used for completion and progress.
+	EffectiveTotalClues int
+	ClueOrder           ClueOrder
+	Mode                string
+}
+
+// ClueOrder controls the order in which groups on a pathway visit its clues.
+// Without Shuffle every group follows index_num; with it every group gets its
+// own permutation at game start, keeping the first and last clue in place
+// when pinned.
+type ClueOrder struct {
+	Shuffle  bool
+	PinFirst bool
+	PinLast  bool
 }
 
 type Clue struct {
@@ -20,16 +70,232 @@ type Clue struct {
 	Index   int
 	Content string
 	QRCode  string
+	Answer  ClueAnswer
+	// Geofence is where answers to the clue must be submitted from, or nil
+	// when they are accepted anywhere.
+	Geofence   *Geofence
+	Checkpoint Checkpoint
+}
+
+// Checkpoint describes where a clue's code is posted, for the people
+// putting it up. Both fields may be empty.
+type Checkpoint struct {
+	Label          string
+	PlacementNotes string
+}
+
+// Geofence is a circle around the spot a clue's code is posted at. Answers
+// from outside it are flagged for review, or rejected when Reject is set.
+type Geofence struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters int
+	Reject       bool
+}
+
+// Clue answer types.
+const (
+	AnswerTypeQR     = "qr"
+	AnswerTypeText   = "text"
+	AnswerTypeChoice = "choice"
+)
+
+// ClueAnswer says how a clue is solved. QR clues take the clue's QR code.
+// Text clues take any of Answers or a full match of Pattern, ignoring case
+// and extra whitespace. Choice clues take the index of CorrectChoice within
+// Choices.
+type ClueAnswer struct {
+	Type          string
+	Answers       []string
+	Pattern       string
+	Choices       []string
+	CorrectChoice int
+}
+
+// ClueEdge leads from a clue on a graph pathway to one of the clues a group
+// may go to next.
+type ClueEdge struct {
+	FromClueID int
+	ToClueID   int
+}
+
+// ScanEvent records a single code submitted by a group, whether or not it
+// was accepted.
+type ScanEvent struct {
+	ID            int64
+	GroupID       int
+	GroupName     string
+	ClueID        *int
+	ClueIdx       int
+	Pathway       string
+	SubmittedCode string
+	Result        string
+	ClientIP      string
+	UserAgent     string
+	CreatedAt     time.Time
+
+	// Latitude, Longitude and AccuracyMeters are the reported device
+	// location, if any.
+	Latitude       *float64
+	Longitude      *float64
+	AccuracyMeters *float64
+	// DistanceMeters is how far the device was from the geofence of the
+	// clue the answer solved.
+	DistanceMeters  *float64
+	OutsideGeofence bool
+}
+
+// AuditEntry records a state-changing action taken by an admin. Before and
+// After hold JSON snapshots of what changed, when the action has them.
+type AuditEntry struct {
+	ID        int64
+	AdminID   *int
+	AdminName string
+	EventID   *int
+	Action    string
+	TargetID  string
+	Before    json.RawMessage
+	After     json.RawMessage
+	CreatedAt time.Time
+}
+
+// Solve is the moment a group submitted the correct code for a clue.
+type Solve struct {
+	GroupID  int
+	ClueID   *int
+	ClueIdx  int
+	SolvedAt time.Time
+}
+
+type Hint struct {
+	ID             int
+	ClueID         int
+	Position       int
+	Content        string
+	PenaltySeconds int
 }
 
 type GameSettings struct {
-	ID          int
-	TotalClues  int
+	EventID     int
 	StartTime   *time.Time
 	GameStarted bool
 	GameEnded   bool
+	ScanRules   ScanRules
+
+	// ScheduledStart is when the scheduler starts the game on its own.
+	ScheduledStart *time.Time
+	// MaxDurationSeconds ends the game automatically once it has run this long.
+	MaxDurationSeconds *int
+
+	// PausedAt is set while the game is paused.
+	PausedAt *time.Time
+	// PausedSeconds is the total length of all finished pauses.
+	PausedSeconds int
+}
+
+// Paused reports whether the game clock is currently frozen.
+func (s *GameSettings) Paused() bool {
+	return s.PausedAt != nil
+}
+
+// EndsAt returns when the game ends automatically, or nil when it has no
+// maximum duration, has not started or is paused. Finished pauses push the
+// end back by their length.
+func (s *GameSettings) EndsAt() *time.Time {
+	if s.StartTime == nil || s.MaxDurationSeconds == nil || s.Paused() {
+		return nil
+	}
+	t := s.StartTime.Add(time.Duration(*s.MaxDurationSeconds+s.PausedSeconds) * time.Second)
+	return &t
+}
+
+// GamePause is an interval during which the game clock was frozen. ResumedAt
+// is nil for the pause in progress.
+type GamePause struct {
+	PausedAt  time.Time
+	ResumedAt *time.Time
+}
+
+// ScanRules configure the consequences of wrong submissions. A zero
+// PenaltySeconds or LockoutAfter disables the corresponding rule.
+type ScanRules struct {
+	// Every wrong submission on a clue beyond the first PenaltyAfter adds
+	// PenaltySeconds to the group's time.
+	PenaltyAfter   int
+	PenaltySeconds int
+	// Every LockoutAfter wrong submissions on a clue lock the group out of
+	// scanning for LockoutSeconds.
+	LockoutAfter   int
+	LockoutSeconds int
+}
+
+// Admin roles, from most to least trusted.
+const (
+	AdminRoleOwner      = "owner"
+	AdminRoleGameMaster = "game_master"
+	AdminRoleClueEditor = "clue_editor"
+	AdminRoleMarshal    = "marshal"
+	AdminRoleViewer     = "viewer"
+)
+
+// AdminRoles lists every admin role, from most to least trusted.
+var AdminRoles = []string{AdminRoleOwner, AdminRoleGameMaster, AdminRoleClueEditor, AdminRoleMarshal, AdminRoleViewer}
+
+// Permission is something an admin may be allowed to do.
+type Permission string
+
+const (
+	// PermView reads the game state, setup and leaderboard.
+	PermView Permission = "view"
+	// PermRunGame starts, pauses, schedules and ends the game and sets its
+	// scan rules.
+	PermRunGame Permission = "run_game"
+	// PermResetGame wipes the progress of every group.
+	PermResetGame Permission = "reset_game"
+	// PermManageGroups adds, removes, imports and exports groups.
+	PermManageGroups Permission = "manage_groups"
+	// PermEditClues changes pathways, clues, hints and QR codes.
+	PermEditClues Permission = "edit_clues"
+	// PermPrintQR downloads QR codes and checkpoint sheets.
+	PermPrintQR Permission = "print_qr"
+	// PermManageEvents creates, clones and archives events and replaces
+	// their setup.
+	PermManageEvents Permission = "manage_events"
+	// PermManageStaff invites and removes admins and changes their roles.
+	PermManageStaff Permission = "manage_staff"
+	// PermViewAudit reads the audit log of admin actions.
+	PermViewAudit Permission = "view_audit"
+)
+
+var rolePermissions = map[string][]Permission{
+	AdminRoleOwner: {
+		PermView, PermRunGame, PermResetGame, PermManageGroups, PermEditClues,
+		PermPrintQR, PermManageEvents, PermManageStaff, PermViewAudit,
+	},
+	AdminRoleGameMaster: {PermView, PermRunGame, PermManageGroups, PermPrintQR, PermViewAudit},
+	AdminRoleClueEditor: {PermView, PermEditClues, PermPrintQR},
+	AdminRoleMarshal:    {PermView, PermPrintQR},
+	AdminRoleViewer:     {PermView},
+}
+
+// ValidAdminRole reports whether role is one of the admin roles.
+func ValidAdminRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions returns what admins with the role may do.
+func RolePermissions(role string) []Permission {
+	return rolePermissions[role]
 }
 
 type Admin struct {
-	ID int
+	ID   int
+	Name string
+	Role string
+}
+
+// Can reports whether the admin's role grants the permission.
+func (a *Admin) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[a.Role], p)
 }
@@ -3,7 +3,11 @@ package services
 import (
 	"context"
 	"cyberhunt/internal/models"
+	"cyberhunt/internal/utils"
 	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
 )
 
 type AdminService struct {
@@ -14,40 +18,206 @@ func NewAdminService(db *sql.DB) *AdminService {
 	return &AdminService{db: db}
 }
 
+// GetAdminByNameAndPassword looks the admin up by name and verifies the
+// password against the stored hash, upgrading legacy plaintext passwords on
+// the first successful login.
 func (s *AdminService) GetAdminByNameAndPassword(ctx context.Context, name, password string) (*models.Admin, error) {
 	var admin models.Admin
+	var stored string
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id FROM admins WHERE name = $1 AND password = $2
-	`, name, password).Scan(&admin.ID)
+		SELECT id, name, role, password FROM admins WHERE name = $1
+	`, name).Scan(&admin.ID, &admin.Name, &admin.Role, &stored)
+	if err == sql.ErrNoRows {
+		utils.RejectPassword(password)
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	ok, needsRehash := utils.CheckPassword(stored, password)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		hash, err := utils.HashPassword(password)
+		if err != nil {
+			return nil, fmt.Errorf("hash password for admin %d: %w", admin.ID, err)
+		}
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE admins SET password = $3 WHERE id = $1 AND password = $2
+		`, admin.ID, stored, hash)
+		if err != nil {
+			return nil, fmt.Errorf("upgrade password for admin %d: %w", admin.ID, err)
+		}
+	}
+
 	return &admin, nil
 }
 
-func (s *AdminService) UpdateTotalClues(ctx context.Context, totalClues int) error {
-	// Update existing settings
-	result, err := s.db.ExecContext(ctx, `
-		UPDATE game_settings SET total_clues = $1 WHERE id = 1
-	`, totalClues)
+// GetAdmin returns the admin with the given ID.
+func (s *AdminService) GetAdmin(ctx context.Context, id int) (*models.Admin, error) {
+	var admin models.Admin
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, role FROM admins WHERE id = $1
+	`, id).Scan(&admin.ID, &admin.Name, &admin.Role)
+	if err == sql.ErrNoRows {
+		return nil, ErrAdminNotFound
+	}
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to fetch admin %d: %w", id, err)
+	}
+	return &admin, nil
+}
+
+// ListAdmins returns every admin ordered by name.
+func (s *AdminService) ListAdmins(ctx context.Context) ([]*models.Admin, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, role FROM admins ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch admins: %w", err)
+	}
+	defer rows.Close()
+
+	admins := []*models.Admin{}
+	for rows.Next() {
+		var a models.Admin
+		if err := rows.Scan(&a.ID, &a.Name, &a.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan admin: %w", err)
+		}
+		admins = append(admins, &a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admins: %w", err)
+	}
+	return admins, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
+// CreateAdmin adds a member of staff who logs in with the given password.
+func (s *AdminService) CreateAdmin(ctx context.Context, name, password, role string) (*models.Admin, error) {
+	if !models.ValidAdminRole(role) {
+		return nil, ErrInvalidAdminRole
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := models.Admin{Name: name, Role: role}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO admins (name, password, role) VALUES ($1, $2, $3) RETURNING id
+	`, name, hash, role).Scan(&admin.ID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return nil, ErrAdminExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin: %w", err)
+	}
+	return &admin, nil
+}
+
+// UpdateAdminRole changes the role of an admin. The last owner keeps their
+// role so that someone can always manage staff.
+func (s *AdminService) UpdateAdminRole(ctx context.Context, id int, role string) error {
+	if !models.ValidAdminRole(role) {
+		return ErrInvalidAdminRole
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// If no rows affected, insert new settings
-	if rowsAffected == 0 {
-		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO game_settings (id, total_clues, game_started, game_ended) VALUES (1, $1, false, false)
-		`, totalClues)
-		if err != nil {
+	if role != models.AdminRoleOwner {
+		if err := checkNotLastOwner(ctx, tx, id); err != nil {
 			return err
 		}
 	}
+	res, err := tx.ExecContext(ctx, `UPDATE admins SET role = $2 WHERE id = $1`, id, role)
+	if err != nil {
+		return fmt.Errorf("failed to update admin %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAdminNotFound
+	}
+	return tx.Commit()
+}
+
+// ResetAdminPassword sets a new password for an admin and logs them out
+// everywhere.
+func (s *AdminService) ResetAdminPassword(ctx context.Context, id int, password string) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE admins SET password = $2 WHERE id = $1`, id, hash)
+	if err != nil {
+		return fmt.Errorf("failed to reset password for admin %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAdminNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE admin_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to revoke sessions of admin %d: %w", id, err)
+	}
+	return tx.Commit()
+}
 
+// DeleteAdmin removes an admin, unless they are the last owner.
+func (s *AdminService) DeleteAdmin(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNotLastOwner(ctx, tx, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM admins WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete admin %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAdminNotFound
+	}
+	return tx.Commit()
+}
+
+// checkNotLastOwner returns ErrLastOwner if the admin is the only owner. The
+// owners stay locked until the transaction ends so that two owners cannot
+// demote each other at once.
+func checkNotLastOwner(ctx context.Context, tx *sql.Tx, id int) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM admins WHERE role = $1 FOR UPDATE
+	`, models.AdminRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to lock owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners, isOwner := 0, false
+	for rows.Next() {
+		var owner int
+		if err := rows.Scan(&owner); err != nil {
+			return fmt.Errorf("failed to scan owner: %w", err)
+		}
+		owners++
+		isOwner = isOwner || owner == id
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating owners: %w", err)
+	}
+	if isOwner && owners == 1 {
+		return ErrLastOwner
+	}
 	return nil
 }
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"crypto/subtle"
+	"cyberhunt/internal/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// normalizeAnswer lowercases a typed answer and collapses its whitespace.
+func normalizeAnswer(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// compileAnswerPattern anchors an answer pattern so it must match the whole
+// normalized answer, ignoring case.
+func compileAnswerPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`(?i)^(?:` + pattern + `)$`)
+}
+
+// NormalizeClueAnswer trims the answer configuration of a clue, defaulting to
+// a QR clue, and checks that the clue can be solved.
+func NormalizeClueAnswer(a *models.ClueAnswer) error {
+	a.Type = strings.ToLower(strings.TrimSpace(a.Type))
+	if a.Type == "" {
+		a.Type = models.AnswerTypeQR
+	}
+	a.Pattern = strings.TrimSpace(a.Pattern)
+
+	answers := []string{}
+	for _, s := range a.Answers {
+		if s = strings.TrimSpace(s); s != "" {
+			answers = append(answers, s)
+		}
+	}
+	a.Answers = answers
+	choices := []string{}
+	for _, s := range a.Choices {
+		if s = strings.TrimSpace(s); s != "" {
+			choices = append(choices, s)
+		}
+	}
+	a.Choices = choices
+
+	switch a.Type {
+	case models.AnswerTypeQR:
+	case models.AnswerTypeText:
+		if len(a.Answers) == 0 && a.Pattern == "" {
+			return fmt.Errorf("%w: text clues need an accepted answer or a pattern", ErrInvalidClueAnswer)
+		}
+		if a.Pattern != "" {
+			if _, err := compileAnswerPattern(a.Pattern); err != nil {
+				return fmt.Errorf("%w: invalid answer pattern: %v", ErrInvalidClueAnswer, err)
+			}
+		}
+	case models.AnswerTypeChoice:
+		if len(a.Choices) < 2 {
+			return fmt.Errorf("%w: choice clues need at least two choices", ErrInvalidClueAnswer)
+		}
+		if a.CorrectChoice < 0 || a.CorrectChoice >= len(a.Choices) {
+			return fmt.Errorf("%w: the correct choice must be one of the choices", ErrInvalidClueAnswer)
+		}
+	default:
+		return fmt.Errorf("%w: answer type must be 'qr', 'text' or 'choice'", ErrInvalidClueAnswer)
+	}
+	return nil
+}
+
+// answerAccepted reports whether a submission solves the clue. Signed QR
+// codes are verified against the event's QR secret, so codes minted before
+// the secret was rotated no longer match.
+func answerAccepted(clue *models.Clue, submission string, qrSecret []byte) bool {
+	switch clue.Answer.Type {
+	case models.AnswerTypeText:
+		got := normalizeAnswer(submission)
+		for _, a := range clue.Answer.Answers {
+			if normalizeAnswer(a) == got {
+				return true
+			}
+		}
+		if clue.Answer.Pattern != "" {
+			re, err := compileAnswerPattern(clue.Answer.Pattern)
+			return err == nil && re.MatchString(got)
+		}
+		return false
+	case models.AnswerTypeChoice:
+		i, err := strconv.Atoi(strings.TrimSpace(submission))
+		return err == nil && i == clue.Answer.CorrectChoice
+	default:
+		if isSignedQRCode(clue.QRCode) {
+			clueID, ok := verifyQRToken(qrSecret, submission)
+			return ok && clueID == clue.ID
+		}
+		return subtle.ConstantTimeCompare([]byte(submission), []byte(clue.QRCode)) == 1
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeClueAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      models.ClueAnswer
+		want    models.ClueAnswer
+		wantErr bool
+	}{
+		{
+			name: "defaults to qr",
+			in:   models.ClueAnswer{},
+			want: models.ClueAnswer{Type: models.AnswerTypeQR, Answers: []string{}, Choices: []string{}},
+		},
+		{
+			name: "trims type, answers and pattern",
+			in:   models.ClueAnswer{Type: " Text ", Answers: []string{" Paris ", "", "  "}, Pattern: " par.* "},
+			want: models.ClueAnswer{Type: models.AnswerTypeText, Answers: []string{"Paris"}, Pattern: "par.*", Choices: []string{}},
+		},
+		{
+			name:    "text without answers",
+			in:      models.ClueAnswer{Type: "text", Answers: []string{" "}},
+			wantErr: true,
+		},
+		{
+			name: "text with only a pattern",
+			in:   models.ClueAnswer{Type: "text", Pattern: `\d+`},
+			want: models.ClueAnswer{Type: models.AnswerTypeText, Answers: []string{}, Pattern: `\d+`, Choices: []string{}},
+		},
+		{
+			name:    "invalid pattern",
+			in:      models.ClueAnswer{Type: "text", Answers: []string{"a"}, Pattern: "(unclosed"},
+			wantErr: true,
+		},
+		{
+			name: "choices are trimmed",
+			in:   models.ClueAnswer{Type: "choice", Choices: []string{" red ", "", "blue"}, CorrectChoice: 1},
+			want: models.ClueAnswer{Type: models.AnswerTypeChoice, Answers: []string{}, Choices: []string{"red", "blue"}, CorrectChoice: 1},
+		},
+		{
+			name:    "one choice",
+			in:      models.ClueAnswer{Type: "choice", Choices: []string{"red", " "}},
+			wantErr: true,
+		},
+		{
+			name:    "correct choice past the end",
+			in:      models.ClueAnswer{Type: "choice", Choices: []string{"red", "blue"}, CorrectChoice: 2},
+			wantErr: true,
+		},
+		{
+			name:    "negative correct choice",
+			in:      models.ClueAnswer{Type: "choice", Choices: []string{"red", "blue"}, CorrectChoice: -1},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			in:      models.ClueAnswer{Type: "photo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			err := NormalizeClueAnswer(&got)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidClueAnswer) {
+					t.Errorf("NormalizeClueAnswer(%+v) = %v, want ErrInvalidClueAnswer", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeClueAnswer(%+v) = %v", tt.in, err)
+			}
+			if got.Type != tt.want.Type || got.Pattern != tt.want.Pattern || got.CorrectChoice != tt.want.CorrectChoice ||
+				!slices.Equal(got.Answers, tt.want.Answers) || !slices.Equal(got.Choices, tt.want.Choices) {
+				t.Errorf("NormalizeClueAnswer(%+v) gave %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswerAccepted(t *testing.T) {
+	text := func(pattern string, answers ...string) *models.Clue {
+		return &models.Clue{Answer: models.ClueAnswer{Type: models.AnswerTypeText, Answers: answers, Pattern: pattern}}
+	}
+	choice := &models.Clue{Answer: models.ClueAnswer{
+		Type: models.AnswerTypeChoice, Choices: []string{"red", "green", "blue"}, CorrectChoice: 1,
+	}}
+	qr := &models.Clue{QRCode: "CLUE-7", Answer: models.ClueAnswer{Type: models.AnswerTypeQR}}
+
+	tests := []struct {
+		name       string
+		clue       *models.Clue
+		submission string
+		want       bool
+	}{
+		{"exact text", text("", "Eiffel Tower"), "Eiffel Tower", true},
+		{"case folded", text("", "Eiffel Tower"), "eiffel TOWER", true},
+		{"whitespace folded", text("", "Eiffel Tower"), "  eiffel \t  tower\n", true},
+		{"configured answer folded", text("", "  EIFFEL   tower "), "eiffel tower", true},
+		{"words must match", text("", "Eiffel Tower"), "eiffeltower", false},
+		{"first of several answers", text("", "Paris", "Lutetia"), "paris", true},
+		{"second of several answers", text("", "Paris", "Lutetia"), "LUTETIA", true},
+		{"none of several answers", text("", "Paris", "Lutetia"), "London", false},
+		{"pattern full match", text(`\d{4}`), "1889", true},
+		{"pattern ignores case", text(`tower \d+`), "TOWER 42", true},
+		{"pattern anchored at the start", text(`\d{4}`), "year 1889", false},
+		{"pattern anchored at the end", text(`\d{4}`), "18890", false},
+		{"pattern alternation anchored", text(`cat|dog`), "catalog", false},
+		{"pattern sees folded whitespace", text(`big ben`), "  big   ben ", true},
+		{"answer or pattern", text(`\d+`, "none"), "None", true},
+		{"invalid pattern never matches", text(`(unclosed`), "(unclosed", false},
+		{"correct choice", choice, "1", true},
+		{"correct choice padded", choice, " 1 ", true},
+		{"wrong choice", choice, "0", false},
+		{"choice out of range", choice, "3", false},
+		{"choice by text", choice, "green", false},
+		{"qr code", qr, "CLUE-7", true},
+		{"qr code is case sensitive", qr, "clue-7", false},
+		{"wrong qr code", qr, "CLUE-8", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerAccepted(tt.clue, tt.submission, nil); got != tt.want {
+				t.Errorf("answerAccepted(%q) = %v, want %v", tt.submission, got, tt.want)
+			}
+		})
+	}
+}
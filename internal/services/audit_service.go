@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditFilter narrows ListAuditEntries. Zero values are ignored. Action
+// matches whole actions or a prefix ending at a dot, so "clue" finds
+// "clue.add" and "clue.delete".
+type AuditFilter struct {
+	EventID  int
+	AdminID  int
+	Action   string
+	TargetID string
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+	Offset   int
+}
+
+type AuditService struct {
+	db *sql.DB
+}
+
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record stores an audit entry. A zero admin or event ID is stored as NULL.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditEntry) error {
+	// JSONB columns reject an empty string, so missing snapshots become NULL
+	nullJSON := func(v []byte) any {
+		if len(v) == 0 {
+			return nil
+		}
+		return string(v)
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO admin_audit_log (admin_id, admin_name, event_id, action, target_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, entry.AdminID, entry.AdminName, entry.EventID, entry.Action, entry.TargetID,
+		nullJSON(entry.Before), nullJSON(entry.After))
+	if err != nil {
+		return fmt.Errorf("record audit entry %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns one page of audit entries, newest first, together
+// with the total number of entries matching the filter.
+func (s *AuditService) ListAuditEntries(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, int, error) {
+	var conds []string
+	var args []any
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.EventID > 0 {
+		addCond("event_id = ?", filter.EventID)
+	}
+	if filter.AdminID > 0 {
+		addCond("admin_id = ?", filter.AdminID)
+	}
+	if filter.Action != "" {
+		addCond("(action = ? OR starts_with(action, ? || '.'))", filter.Action)
+	}
+	if filter.TargetID != "" {
+		addCond("target_id = ?", filter.TargetID)
+	}
+	if filter.Since != nil {
+		addCond("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCond("created_at < ?", *filter.Until)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM admin_audit_log `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, admin_id, admin_name, event_id, action, target_id, before, after, created_at
+		FROM admin_audit_log
+		`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(
+			&e.ID, &e.AdminID, &e.AdminName, &e.EventID, &e.Action, &e.TargetID,
+			&before, &after, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// clueGraph is the clue graph of one graph pathway. Its start is the clue
+// with the lowest index_num; clues without outgoing edges are finish clues.
+type clueGraph struct {
+	start int
+	clues map[int]*models.Clue
+	next  map[int][]int
+	// dist is the number of scans from a clue to the nearest finish clue.
+	// Clues that cannot reach a finish clue are missing.
+	dist map[int]int
+}
+
+// loadClueGraph reads the clues and edges of a pathway. Edges leaving the
+// pathway are ignored.
+func loadClueGraph(ctx context.Context, q queryer, eventID int, pathway string) (*clueGraph, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+clueColumns+` FROM clues WHERE event_id = $1 AND pathway = $2 ORDER BY index_num
+	`, eventID, pathway)
+	if err != nil {
+		return nil, fmt.Errorf("query clues of pathway %s: %w", pathway, err)
+	}
+	var clues []models.Clue
+	for rows.Next() {
+		var clue models.Clue
+		if err := scanClue(rows, &clue); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan clue: %w", err)
+		}
+		clues = append(clues, clue)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clues: %w", err)
+	}
+
+	rows, err = q.QueryContext(ctx, `
+		SELECT e.from_clue_id, e.to_clue_id
+		FROM clue_edges e
+		JOIN clues f ON f.id = e.from_clue_id
+		JOIN clues t ON t.id = e.to_clue_id
+		WHERE f.event_id = $1 AND f.pathway = $2 AND t.event_id = $1 AND t.pathway = $2
+		ORDER BY e.from_clue_id, e.to_clue_id
+	`, eventID, pathway)
+	if err != nil {
+		return nil, fmt.Errorf("query clue edges of pathway %s: %w", pathway, err)
+	}
+	defer rows.Close()
+	var edges []models.ClueEdge
+	for rows.Next() {
+		var e models.ClueEdge
+		if err := rows.Scan(&e.FromClueID, &e.ToClueID); err != nil {
+			return nil, fmt.Errorf("scan clue edge: %w", err)
+		}
+		edges = append(edges, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clue edges: %w", err)
+	}
+
+	return newClueGraph(clues, edges), nil
+}
+
+// newClueGraph builds the graph of a pathway from its clues, ordered by
+// index_num, and its edges. Edges to or from other clues are ignored.
+func newClueGraph(clues []models.Clue, edges []models.ClueEdge) *clueGraph {
+	g := &clueGraph{clues: make(map[int]*models.Clue, len(clues)), next: make(map[int][]int)}
+	for i := range clues {
+		if len(g.clues) == 0 {
+			g.start = clues[i].ID
+		}
+		g.clues[clues[i].ID] = &clues[i]
+	}
+
+	prev := make(map[int][]int)
+	for _, e := range edges {
+		if g.clues[e.FromClueID] == nil || g.clues[e.ToClueID] == nil {
+			continue
+		}
+		g.next[e.FromClueID] = append(g.next[e.FromClueID], e.ToClueID)
+		prev[e.ToClueID] = append(prev[e.ToClueID], e.FromClueID)
+	}
+
+	// Walk the edges backwards from every finish clue at once
+	g.dist = make(map[int]int, len(g.clues))
+	var queue []int
+	for id := range g.clues {
+		if g.finish(id) {
+			g.dist[id] = 0
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, p := range prev[id] {
+			if _, seen := g.dist[p]; !seen {
+				g.dist[p] = g.dist[id] + 1
+				queue = append(queue, p)
+			}
+		}
+	}
+	return g
+}
+
+func (g *clueGraph) finish(id int) bool {
+	return len(g.next[id]) == 0
+}
+
+// length is the number of scans on the shortest route from the start to a
+// finish clue, and at least 1.
+func (g *clueGraph) length() int {
+	if d := g.dist[g.start]; d > 0 {
+		return d
+	}
+	return 1
+}
+
+// progress turns the distance from a clue to the finish into a position out
+// of length(), so graph pathways can share the progress display and ranking
+// of linear ones. Detours and dead ends count as no progress.
+func (g *clueGraph) progress(id int) int {
+	if g.finish(id) {
+		return g.length()
+	}
+	d, ok := g.dist[id]
+	if !ok {
+		return 0
+	}
+	return max(g.length()-d, 0)
+}
+
+// match returns the clue an edge from the given clue leads to that the
+// submission solves, or 0 when there is none. Each target is checked
+// according to its own answer type.
+func (g *clueGraph) match(from int, submission string, qrSecret []byte) int {
+	found := 0
+	for _, to := range g.next[from] {
+		if answerAccepted(g.clues[to], submission, qrSecret) && found == 0 {
+			found = to
+		}
+	}
+	return found
+}
+
+// graphPathwayLengths returns the length of every graph pathway of the
+// event keyed by name.
+func graphPathwayLengths(ctx context.Context, q queryer, eventID int) (map[string]int, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT name FROM pathways WHERE event_id = $1 AND mode = $2
+	`, eventID, models.PathwayModeGraph)
+	if err != nil {
+		return nil, fmt.Errorf("query graph pathways: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan graph pathway: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating graph pathways: %w", err)
+	}
+
+	lengths := make(map[string]int, len(names))
+	for _, name := range names {
+		g, err := loadClueGraph(ctx, q, eventID, name)
+		if err != nil {
+			return nil, err
+		}
+		lengths[name] = g.length()
+	}
+	return lengths, nil
+}
+
+// ClueGraphNode describes a clue within its pathway's graph.
+type ClueGraphNode struct {
+	ClueID int  `json:"clue_id"`
+	Start  bool `json:"start"`
+	Finish bool `json:"finish"`
+	// Distance is the number of scans to the nearest finish clue, or nil
+	// when no finish clue can be reached.
+	Distance *int `json:"distance"`
+}
+
+// ClueGraph is the clue graph of a pathway as shown to admins.
+type ClueGraph struct {
+	Length int               `json:"length"`
+	Nodes  []ClueGraphNode   `json:"nodes"`
+	Edges  []models.ClueEdge `json:"edges"`
+}
+
+// GetClueGraph returns the graph of a pathway with the distance from every
+// clue to the finish.
+func (s *ClueService) GetClueGraph(ctx context.Context, eventID int, pathway string) (*ClueGraph, error) {
+	g, err := loadClueGraph(ctx, s.db, eventID, pathway)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ClueGraph{Length: g.length(), Nodes: []ClueGraphNode{}, Edges: []models.ClueEdge{}}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM clues WHERE event_id = $1 AND pathway = $2 ORDER BY index_num
+	`, eventID, pathway)
+	if err != nil {
+		return nil, fmt.Errorf("query clues of pathway %s: %w", pathway, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan clue: %w", err)
+		}
+		node := ClueGraphNode{ClueID: id, Start: id == g.start, Finish: g.finish(id)}
+		if d, ok := g.dist[id]; ok {
+			node.Distance = &d
+		}
+		out.Nodes = append(out.Nodes, node)
+		for _, to := range g.next[id] {
+			out.Edges = append(out.Edges, models.ClueEdge{FromClueID: id, ToClueID: to})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clues: %w", err)
+	}
+
+	return out, nil
+}
+
+// AddClueEdge lets groups go from one clue to another. Both clues must be on
+// the same pathway of the event.
+func (s *ClueService) AddClueEdge(ctx context.Context, eventID, fromID, toID int) error {
+	if fromID == toID {
+		return ErrInvalidClueEdge
+	}
+
+	var found int
+	var samePathway bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT pathway) = 1
+		FROM clues
+		WHERE event_id = $1 AND id IN ($2, $3)
+	`, eventID, fromID, toID).Scan(&found, &samePathway)
+	if err != nil {
+		return err
+	}
+	if found != 2 {
+		return ErrClueNotFound
+	}
+	if !samePathway {
+		return ErrInvalidClueEdge
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO clue_edges (from_clue_id, to_clue_id) VALUES ($1, $2)
+	`, fromID, toID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrClueEdgeExists
+	}
+	return err
+}
+
+func (s *ClueService) DeleteClueEdge(ctx context.Context, eventID, fromID, toID int) error {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM clue_edges e
+		USING clues c
+		WHERE c.id = e.from_clue_id AND c.event_id = $1 AND e.from_clue_id = $2 AND e.to_clue_id = $3
+	`, eventID, fromID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to delete clue edge %d->%d: %w", fromID, toID, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrClueEdgeNotFound
+	}
+	return nil
+}
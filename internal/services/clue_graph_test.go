@@ -0,0 +1,142 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"maps"
+	"testing"
+)
+
+// testClueGraph builds a graph of clues 1 to n, in index order, with the
+// given edges.
+func testClueGraph(n int, edges [][2]int) *clueGraph {
+	clues := make([]models.Clue, n)
+	for i := range clues {
+		clues[i] = models.Clue{ID: i + 1, Index: i}
+	}
+	var es []models.ClueEdge
+	for _, e := range edges {
+		es = append(es, models.ClueEdge{FromClueID: e[0], ToClueID: e[1]})
+	}
+	return newClueGraph(clues, es)
+}
+
+func TestClueGraph(t *testing.T) {
+	tests := []struct {
+		name  string
+		clues int
+		edges [][2]int
+		// dist leaves out clues that cannot reach a finish clue
+		dist     map[int]int
+		length   int
+		progress map[int]int
+	}{
+		{
+			name:     "single clue",
+			clues:    1,
+			dist:     map[int]int{1: 0},
+			length:   1,
+			progress: map[int]int{1: 1},
+		},
+		{
+			name:     "chain",
+			clues:    3,
+			edges:    [][2]int{{1, 2}, {2, 3}},
+			dist:     map[int]int{1: 2, 2: 1, 3: 0},
+			length:   2,
+			progress: map[int]int{1: 0, 2: 1, 3: 2},
+		},
+		{
+			name:     "branch and rejoin",
+			clues:    5,
+			edges:    [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 5}, {5, 4}},
+			dist:     map[int]int{1: 2, 2: 1, 3: 2, 4: 0, 5: 1},
+			length:   2,
+			progress: map[int]int{1: 0, 2: 1, 3: 0, 4: 2, 5: 1},
+		},
+		{
+			name:     "several finish clues",
+			clues:    4,
+			edges:    [][2]int{{1, 2}, {1, 3}, {3, 4}},
+			dist:     map[int]int{1: 1, 2: 0, 3: 1, 4: 0},
+			length:   1,
+			progress: map[int]int{1: 0, 2: 1, 3: 0, 4: 1},
+		},
+		{
+			name:     "dead-end branch",
+			clues:    5,
+			edges:    [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 5}, {5, 3}},
+			dist:     map[int]int{1: 2, 2: 1, 4: 0},
+			length:   2,
+			progress: map[int]int{1: 0, 2: 1, 3: 0, 4: 2, 5: 0},
+		},
+		{
+			name:     "unreachable finish",
+			clues:    4,
+			edges:    [][2]int{{1, 2}, {2, 1}, {3, 4}},
+			dist:     map[int]int{3: 1, 4: 0},
+			length:   1,
+			progress: map[int]int{1: 0, 2: 0, 3: 0, 4: 1},
+		},
+		{
+			name:     "cycle with an exit",
+			clues:    3,
+			edges:    [][2]int{{1, 2}, {2, 1}, {2, 3}},
+			dist:     map[int]int{1: 2, 2: 1, 3: 0},
+			length:   2,
+			progress: map[int]int{1: 0, 2: 1, 3: 2},
+		},
+		{
+			name:     "edges leaving the pathway",
+			clues:    2,
+			edges:    [][2]int{{1, 2}, {2, 99}, {98, 1}},
+			dist:     map[int]int{1: 1, 2: 0},
+			length:   1,
+			progress: map[int]int{1: 0, 2: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testClueGraph(tt.clues, tt.edges)
+			if g.start != 1 {
+				t.Errorf("start = %d, want 1", g.start)
+			}
+			if !maps.Equal(g.dist, tt.dist) {
+				t.Errorf("dist = %v, want %v", g.dist, tt.dist)
+			}
+			if got := g.length(); got != tt.length {
+				t.Errorf("length() = %d, want %d", got, tt.length)
+			}
+			for id, want := range tt.progress {
+				if got := g.progress(id); got != want {
+					t.Errorf("progress(%d) = %d, want %d", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClueGraphMatch(t *testing.T) {
+	g := testClueGraph(4, [][2]int{{1, 2}, {1, 3}, {3, 4}})
+	for id, code := range map[int]string{1: "one", 2: "two", 3: "three", 4: "four"} {
+		g.clues[id].QRCode = code
+		g.clues[id].Answer.Type = models.AnswerTypeQR
+	}
+
+	tests := []struct {
+		from       int
+		submission string
+		want       int
+	}{
+		{1, "two", 2},
+		{1, "three", 3},
+		{1, "four", 0}, // not reachable in one scan
+		{1, "one", 0},  // the clue itself
+		{3, "four", 4},
+		{4, "one", 0}, // finish clues lead nowhere
+	}
+	for _, tt := range tests {
+		if got := g.match(tt.from, tt.submission, nil); got != tt.want {
+			t.Errorf("match(%d, %q) = %d, want %d", tt.from, tt.submission, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/lib/pq"
+)
+
+// shuffleClues returns a random permutation of clueIDs, which are ordered by
+// index_num. Pinned first and last clues keep their place.
+func shuffleClues(clueIDs []int, order models.ClueOrder) []int {
+	out := append([]int(nil), clueIDs...)
+	lo, hi := 0, len(out)
+	if order.PinFirst && hi-lo > 0 {
+		lo++
+	}
+	if order.PinLast && hi-lo > 0 {
+		hi--
+	}
+	middle := out[lo:hi]
+	rand.Shuffle(len(middle), func(i, j int) { middle[i], middle[j] = middle[j], middle[i] })
+	return out
+}
+
+// assignClueOrders generates a fresh clue order for every group of the event
+// on a shuffled pathway, or only for groupID when it is non-zero, replacing
+// any earlier one. Groups on other pathways follow index_num and get no
+// stored order.
+func assignClueOrders(ctx context.Context, tx *sql.Tx, eventID, groupID int) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM group_clue_order
+		WHERE group_id IN (SELECT id FROM groups WHERE event_id = $1 AND ($2 = 0 OR id = $2))
+	`, eventID, groupID)
+	if err != nil {
+		return fmt.Errorf("clear clue orders: %w", err)
+	}
+
+	type shuffled struct {
+		name  string
+		order models.ClueOrder
+	}
+	var pathways []shuffled
+	rows, err := tx.QueryContext(ctx, `
+		SELECT name, pin_first_clue, pin_last_clue
+		FROM pathways
+		WHERE event_id = $1 AND shuffle_clues
+	`, eventID)
+	if err != nil {
+		return fmt.Errorf("query shuffled pathways: %w", err)
+	}
+	for rows.Next() {
+		p := shuffled{order: models.ClueOrder{Shuffle: true}}
+		if err := rows.Scan(&p.name, &p.order.PinFirst, &p.order.PinLast); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan shuffled pathway: %w", err)
+		}
+		pathways = append(pathways, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating shuffled pathways: %w", err)
+	}
+
+	for _, p := range pathways {
+		var clueIDs, groupIDs []int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT
+				COALESCE((SELECT array_agg(id ORDER BY index_num) FROM clues WHERE event_id = $1 AND pathway = $2), '{}'),
+				COALESCE((SELECT array_agg(id ORDER BY id) FROM groups WHERE event_id = $1 AND pathway = $2 AND ($3 = 0 OR id = $3)), '{}')
+		`, eventID, p.name, groupID).Scan(pq.Array(&clueIDs), pq.Array(&groupIDs))
+		if err != nil {
+			return fmt.Errorf("query pathway %s: %w", p.name, err)
+		}
+		if len(clueIDs) == 0 {
+			continue
+		}
+
+		ids := make([]int, len(clueIDs))
+		for i, id := range clueIDs {
+			ids[i] = int(id)
+		}
+		for _, groupID := range groupIDs {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO group_clue_order (group_id, position, clue_id)
+				SELECT $1, o.pos - 1, o.clue_id
+				FROM unnest($2::int[]) WITH ORDINALITY AS o(clue_id, pos)
+			`, groupID, pq.Array(shuffleClues(ids, p.order)))
+			if err != nil {
+				return fmt.Errorf("store clue order for group %d: %w", groupID, err)
+			}
+		}
+	}
+
+	return nil
+}
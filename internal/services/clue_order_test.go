@@ -0,0 +1,61 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestShuffleClues(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 6} {
+		for _, order := range []models.ClueOrder{
+			{Shuffle: true},
+			{Shuffle: true, PinFirst: true},
+			{Shuffle: true, PinLast: true},
+			{Shuffle: true, PinFirst: true, PinLast: true},
+		} {
+			t.Run(fmt.Sprintf("%d clues pin first %v last %v", n, order.PinFirst, order.PinLast), func(t *testing.T) {
+				ids := make([]int, n)
+				for i := range ids {
+					ids[i] = 10 * (i + 1)
+				}
+				in := slices.Clone(ids)
+
+				seen := map[string]bool{}
+				for range 200 {
+					out := shuffleClues(ids, order)
+					if !slices.Equal(ids, in) {
+						t.Fatalf("shuffleClues changed its input to %v", ids)
+					}
+					if sorted := slices.Sorted(slices.Values(out)); !slices.Equal(sorted, in) {
+						t.Fatalf("shuffleClues(%v) = %v, not a permutation", in, out)
+					}
+					if n > 0 && order.PinFirst && out[0] != in[0] {
+						t.Fatalf("shuffleClues(%v) = %v moved the pinned first clue", in, out)
+					}
+					if n > 0 && order.PinLast && out[n-1] != in[n-1] {
+						t.Fatalf("shuffleClues(%v) = %v moved the pinned last clue", in, out)
+					}
+					seen[fmt.Sprint(out)] = true
+				}
+
+				// With three or more unpinned clues, 200 shuffles all
+				// coming out the same is practically impossible
+				free := n
+				if order.PinFirst && free > 0 {
+					free--
+				}
+				if order.PinLast && free > 0 {
+					free--
+				}
+				if free >= 3 && len(seen) < 2 {
+					t.Errorf("shuffleClues(%v) always returned %v", in, seen)
+				}
+				if free <= 1 && len(seen) != 1 {
+					t.Errorf("shuffleClues(%v) returned several orders with nothing to shuffle: %v", in, seen)
+				}
+			})
+		}
+	}
+}
@@ -5,8 +5,86 @@ import (
 	"cyberhunt/internal/models"
 	"database/sql"
 	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// clueColumns lists the columns scanClue reads, in order.
+const clueColumns = `id, pathway, index_num, content, qrcode,
+	answer_type, answers, answer_pattern, choices, correct_choice,
+	latitude, longitude, radius_meters, geofence_reject,
+	checkpoint_label, placement_notes`
+
+func scanClue(row interface{ Scan(dest ...any) error }, clue *models.Clue) error {
+	var lat, lng sql.NullFloat64
+	var radius sql.NullInt64
+	var reject bool
+	err := row.Scan(
+		&clue.ID, &clue.Pathway, &clue.Index, &clue.Content, &clue.QRCode,
+		&clue.Answer.Type, pq.Array(&clue.Answer.Answers), &clue.Answer.Pattern,
+		pq.Array(&clue.Answer.Choices), &clue.Answer.CorrectChoice,
+		&lat, &lng, &radius, &reject,
+		&clue.Checkpoint.Label, &clue.Checkpoint.PlacementNotes,
+	)
+	if err != nil {
+		return err
+	}
+	clue.Geofence = nil
+	if lat.Valid && lng.Valid && radius.Valid {
+		clue.Geofence = &models.Geofence{
+			Latitude:     lat.Float64,
+			Longitude:    lng.Float64,
+			RadiusMeters: int(radius.Int64),
+			Reject:       reject,
+		}
+	}
+	return nil
+}
+
+// geofenceArgs splits an optional geofence into the latitude, longitude,
+// radius_meters and geofence_reject column values.
+func geofenceArgs(fence *models.Geofence) (lat, lng, radius any, reject bool) {
+	if fence == nil {
+		return nil, nil, nil, false
+	}
+	return fence.Latitude, fence.Longitude, fence.RadiusMeters, fence.Reject
+}
+
+// currentClue loads the clue the group is currently working on. On a graph
+// pathway that is the clue it last reached, or the pathway's first clue by
+// index_num before that. Otherwise it is the clue at its position in the
+// group's own clue order when the pathway is shuffled, or else the clue with
+// that index_num.
+func currentClue(ctx context.Context, q queryRower, g *models.Group) (*models.Clue, error) {
+	var clue models.Clue
+	err := scanClue(q.QueryRowContext(ctx, `
+		SELECT `+clueColumns+`
+		FROM clues
+		WHERE id = COALESCE(
+			$5::int,
+			(SELECT clue_id FROM group_clue_order WHERE group_id = $1 AND position = $4),
+			(SELECT c.id
+			 FROM clues c
+			 JOIN pathways p ON p.event_id = c.event_id AND p.name = c.pathway
+			 WHERE c.event_id = $2 AND c.pathway = $3 AND (p.mode = 'graph' OR c.index_num = $4)
+			 ORDER BY c.index_num
+			 LIMIT 1)
+		)
+	`, g.ID, g.EventID, g.Pathway, g.CurrentClueIdx, g.CurrentClueID), &clue)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("clue not found for pathway=%s index=%d: %w", g.Pathway, g.CurrentClueIdx, ErrClueNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query clue: %w", err)
+	}
+	return &clue, nil
+}
+
 type ClueService struct {
 	db *sql.DB
 }
@@ -15,15 +93,13 @@ func NewClueService(db *sql.DB) *ClueService {
 	return &ClueService{db: db}
 }
 
-func (s *ClueService) GetClueByPathwayAndIndex(ctx context.Context, pathway string, index int) (*models.Clue, error) {
+func (s *ClueService) GetClueByPathwayAndIndex(ctx context.Context, eventID int, pathway string, index int) (*models.Clue, error) {
 	var clue models.Clue
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, pathway, index_num, content, qrcode
+	err := scanClue(s.db.QueryRowContext(ctx, `
+		SELECT `+clueColumns+`
 		FROM clues
-		WHERE pathway = $1 AND index_num = $2
-	`, pathway, index).Scan(
-		&clue.ID, &clue.Pathway, &clue.Index, &clue.Content, &clue.QRCode,
-	)
+		WHERE event_id = $1 AND pathway = $2 AND index_num = $3
+	`, eventID, pathway, index), &clue)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("clue not found for pathway=%s, index=%d", pathway, index)
@@ -35,25 +111,70 @@ func (s *ClueService) GetClueByPathwayAndIndex(ctx context.Context, pathway stri
 	return &clue, nil
 }
 
-func (s *ClueService) ClearClues(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM clues")
-	return err
+// GetCurrentClue returns the clue the group is currently working on.
+func (s *ClueService) GetCurrentClue(ctx context.Context, group *models.Group) (*models.Clue, error) {
+	return currentClue(ctx, s.db, group)
 }
 
-func (s *ClueService) AddClue(ctx context.Context, pathway string, index int, content, qrCode string) error {
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO clues (pathway, index_num, content, qrcode)
-		VALUES ($1, $2, $3, $4)
-	`, pathway, index, content, qrCode)
+func (s *ClueService) ClearClues(ctx context.Context, eventID int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM clues WHERE event_id = $1", eventID)
 	return err
 }
 
-func (s *ClueService) GetAllClues(ctx context.Context) ([]*models.Clue, error) {
+// mintMissingQRCode gives a QR clue saved without a code a signed one.
+func mintMissingQRCode(ctx context.Context, tx *sql.Tx, eventID, clueID int, qrCode string, answer models.ClueAnswer) error {
+	if answer.Type != models.AnswerTypeQR || qrCode != "" {
+		return nil
+	}
+	secret, err := setQRSecret(ctx, tx, eventID, false)
+	if err != nil {
+		return err
+	}
+	return mintClueQRCode(ctx, tx, eventID, clueID, secret)
+}
+
+// AddClue creates a clue. A QR clue without a code gets a signed one.
+func (s *ClueService) AddClue(ctx context.Context, eventID int, pathway string, index int, content, qrCode string, answer models.ClueAnswer, fence *models.Geofence, checkpoint models.Checkpoint) error {
+	if err := NormalizeClueAnswer(&answer); err != nil {
+		return err
+	}
+	qrCode = strings.TrimSpace(qrCode)
+	lat, lng, radius, reject := geofenceArgs(fence)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO clues (event_id, pathway, index_num, content, qrcode,
+		                   answer_type, answers, answer_pattern, choices, correct_choice,
+		                   latitude, longitude, radius_meters, geofence_reject,
+		                   checkpoint_label, placement_notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
+	`, eventID, pathway, index, content, qrCode,
+		answer.Type, pq.Array(answer.Answers), answer.Pattern, pq.Array(answer.Choices), answer.CorrectChoice,
+		lat, lng, radius, reject,
+		checkpoint.Label, checkpoint.PlacementNotes).Scan(&id)
+	if err != nil {
+		return err
+	}
+	if err := mintMissingQRCode(ctx, tx, eventID, id, qrCode, answer); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *ClueService) GetAllClues(ctx context.Context, eventID int) ([]*models.Clue, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, pathway, index_num, content, qrcode
+		SELECT `+clueColumns+`
 		FROM clues
+		WHERE event_id = $1
 		ORDER BY pathway, index_num
-	`)
+	`, eventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch clues: %w", err)
 	}
@@ -62,7 +183,7 @@ func (s *ClueService) GetAllClues(ctx context.Context) ([]*models.Clue, error) {
 	var clues []*models.Clue
 	for rows.Next() {
 		var clue models.Clue
-		err := rows.Scan(&clue.ID, &clue.Pathway, &clue.Index, &clue.Content, &clue.QRCode)
+		err := scanClue(rows, &clue)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan clue: %w", err)
 		}
@@ -76,38 +197,59 @@ func (s *ClueService) GetAllClues(ctx context.Context) ([]*models.Clue, error) {
 	return clues, nil
 }
 
-func (s *ClueService) UpdateClue(ctx context.Context, id int, pathway string, index int, content, qrCode string) error {
-	_, err := s.db.ExecContext(ctx, `
+// UpdateClue edits a clue. A QR clue saved without a code gets a newly
+// minted signed one.
+func (s *ClueService) UpdateClue(ctx context.Context, eventID, id int, pathway string, index int, content, qrCode string, answer models.ClueAnswer, fence *models.Geofence, checkpoint models.Checkpoint) error {
+	if err := NormalizeClueAnswer(&answer); err != nil {
+		return err
+	}
+	qrCode = strings.TrimSpace(qrCode)
+	lat, lng, radius, reject := geofenceArgs(fence)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
 		UPDATE clues
-		SET pathway = $2, index_num = $3, content = $4, qrcode = $5
-		WHERE id = $1
-	`, id, pathway, index, content, qrCode)
+		SET pathway = $2, index_num = $3, content = $4, qrcode = $5,
+		    answer_type = $7, answers = $8, answer_pattern = $9, choices = $10, correct_choice = $11,
+		    latitude = $12, longitude = $13, radius_meters = $14, geofence_reject = $15,
+		    checkpoint_label = $16, placement_notes = $17
+		WHERE id = $1 AND event_id = $6
+	`, id, pathway, index, content, qrCode, eventID,
+		answer.Type, pq.Array(answer.Answers), answer.Pattern, pq.Array(answer.Choices), answer.CorrectChoice,
+		lat, lng, radius, reject,
+		checkpoint.Label, checkpoint.PlacementNotes)
 	if err != nil {
 		return fmt.Errorf("failed to update clue with id %d: %w", id, err)
 	}
-	return nil
+	if err := mintMissingQRCode(ctx, tx, eventID, id, qrCode, answer); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (s *ClueService) DeleteClue(ctx context.Context, id int) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM clues WHERE id = $1", id)
+func (s *ClueService) DeleteClue(ctx context.Context, eventID, id int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM clues WHERE id = $1 AND event_id = $2", id, eventID)
 	if err != nil {
 		return fmt.Errorf("failed to delete clue with id %d: %w", id, err)
 	}
 	return nil
 }
 
-func (s *ClueService) GetClueByID(ctx context.Context, id int) (*models.Clue, error) {
+func (s *ClueService) GetClueByID(ctx context.Context, eventID, id int) (*models.Clue, error) {
 	var clue models.Clue
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, pathway, index_num, content, qrcode
+	err := scanClue(s.db.QueryRowContext(ctx, `
+		SELECT `+clueColumns+`
 		FROM clues
-		WHERE id = $1
-	`, id).Scan(
-		&clue.ID, &clue.Pathway, &clue.Index, &clue.Content, &clue.QRCode,
-	)
+		WHERE id = $1 AND event_id = $2
+	`, id, eventID), &clue)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("clue not found with id=%d", id)
+		return nil, fmt.Errorf("clue not found with id=%d: %w", id, ErrClueNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch clue (id=%d): %w", id, err)
@@ -2,8 +2,36 @@ package services
 
 import "errors"
 
+var ErrInvalidCredentials = errors.New("invalid name or password")
 var ErrGroupExists = errors.New("this group already exists")
 var ErrGameAlreadyStarted = errors.New("game already started")
 var ErrNoSettingsRow = errors.New("no game settings row found")
 var ErrGameAlreadyEnded = errors.New("game has already ended")
 var ErrGameNotStarted = errors.New("game has not started yet")
+var ErrGamePaused = errors.New("game is paused")
+var ErrGameNotPaused = errors.New("game is not paused")
+var ErrPathwayExists = errors.New("this pathway already exists")
+var ErrPathwayNotFound = errors.New("pathway not found")
+var ErrPathwayInUse = errors.New("pathway is still used by groups or clues")
+var ErrInvalidPathway = errors.New("invalid pathway")
+var ErrWrongAnswer = errors.New("wrong answer")
+var ErrGroupCompleted = errors.New("group already completed")
+var ErrClueNotFound = errors.New("clue not found")
+var ErrHintExists = errors.New("a hint already exists at this position")
+var ErrHintNotFound = errors.New("hint not found")
+var ErrNoMoreHints = errors.New("no more hints for this clue")
+var ErrGroupLocked = errors.New("group is temporarily locked out of scanning")
+var ErrEventExists = errors.New("this event already exists")
+var ErrEventNotFound = errors.New("event not found")
+var ErrEventArchived = errors.New("event is archived")
+var ErrEventRequired = errors.New("an event must be selected")
+var ErrClueEdgeExists = errors.New("this clue edge already exists")
+var ErrClueEdgeNotFound = errors.New("clue edge not found")
+var ErrInvalidClueEdge = errors.New("clue edges must join two different clues on the same pathway")
+var ErrInvalidClueAnswer = errors.New("invalid clue answer")
+var ErrOutsideGeofence = errors.New("answer submitted outside the checkpoint geofence")
+var ErrAdminExists = errors.New("an admin with this name already exists")
+var ErrAdminNotFound = errors.New("admin not found")
+var ErrInvalidAdminRole = errors.New("invalid admin role")
+var ErrLastOwner = errors.New("the last owner cannot be removed or demoted")
+var ErrSessionRevoked = errors.New("session has been revoked or has expired")
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type EventService struct {
+	db *sql.DB
+}
+
+func NewEventService(db *sql.DB) *EventService {
+	return &EventService{db: db}
+}
+
+// ListEvents returns the events oldest first. Archived events are only
+// included when includeArchived is set.
+func (s *EventService) ListEvents(ctx context.Context, includeArchived bool) ([]*models.Event, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, archived, created_at
+		FROM events
+		WHERE $1 OR NOT archived
+		ORDER BY id
+	`, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.Event{}
+	for rows.Next() {
+		var e models.Event
+		if err := rows.Scan(&e.ID, &e.Name, &e.Archived, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+	return events, nil
+}
+
+func (s *EventService) GetEvent(ctx context.Context, id int) (*models.Event, error) {
+	var e models.Event
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, archived, created_at FROM events WHERE id = $1
+	`, id).Scan(&e.ID, &e.Name, &e.Archived, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event %d: %w", id, err)
+	}
+	return &e, nil
+}
+
+// DefaultEvent returns the oldest event that is not archived. It is used
+// whenever a request does not name an event.
+func (s *EventService) DefaultEvent(ctx context.Context) (*models.Event, error) {
+	var e models.Event
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, archived, created_at
+		FROM events
+		WHERE NOT archived
+		ORDER BY id
+		LIMIT 1
+	`).Scan(&e.ID, &e.Name, &e.Archived, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch default event: %w", err)
+	}
+	return &e, nil
+}
+
+// createEvent inserts an event together with its settings row.
+func createEvent(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO events (name) VALUES ($1) RETURNING id
+	`, name).Scan(&id)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return 0, ErrEventExists
+	}
+	if err != nil {
+		return 0, fmt.Errorf("insert event: %w", err)
+	}
+	return id, nil
+}
+
+// CreateEvent creates an empty event with default settings and returns its ID.
+func (s *EventService) CreateEvent(ctx context.Context, name string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := createEvent(ctx, tx, name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO game_settings (event_id) VALUES ($1)`, id); err != nil {
+		return 0, fmt.Errorf("insert game settings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// CloneEvent creates a new event from the configuration of an existing one:
+// its settings (without any schedule), pathways, clues, hints and clue
+// edges. With includeGroups the groups are copied too, with their progress
+// reset.
+func (s *EventService) CloneEvent(ctx context.Context, sourceID int, name string, includeGroups bool) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1)`, sourceID).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrEventNotFound
+	}
+
+	id, err := createEvent(ctx, tx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	type copyStep struct{ what, query string }
+	steps := []copyStep{
+		{"settings", `
+			INSERT INTO game_settings (event_id, wrong_scan_penalty_after, wrong_scan_penalty_seconds,
+			    lockout_after, lockout_seconds, max_duration_seconds)
+			SELECT $2, wrong_scan_penalty_after, wrong_scan_penalty_seconds,
+			       lockout_after, lockout_seconds, max_duration_seconds
+			FROM game_settings WHERE event_id = $1
+		`},
+		{"pathways", `
+			INSERT INTO pathways (event_id, name, color, description, active, total_clues,
+			    shuffle_clues, pin_first_clue, pin_last_clue, mode)
+			SELECT $2, name, color, description, active, total_clues,
+			       shuffle_clues, pin_first_clue, pin_last_clue, mode
+			FROM pathways WHERE event_id = $1
+			ORDER BY id
+		`},
+		{"clues", `
+			INSERT INTO clues (event_id, pathway, index_num, content, qrcode,
+			    answer_type, answers, answer_pattern, choices, correct_choice,
+			    latitude, longitude, radius_meters, geofence_reject,
+			    checkpoint_label, placement_notes)
+			SELECT $2, pathway, index_num, content, qrcode,
+			       answer_type, answers, answer_pattern, choices, correct_choice,
+			       latitude, longitude, radius_meters, geofence_reject,
+			       checkpoint_label, placement_notes
+			FROM clues WHERE event_id = $1
+			ORDER BY id
+		`},
+		{"hints", `
+			INSERT INTO clue_hints (clue_id, position, content, penalty_seconds)
+			SELECT nc.id, h.position, h.content, h.penalty_seconds
+			FROM clue_hints h
+			JOIN clues oc ON oc.id = h.clue_id
+			JOIN clues nc ON nc.event_id = $2 AND nc.pathway = oc.pathway AND nc.index_num = oc.index_num
+			WHERE oc.event_id = $1
+		`},
+		{"clue edges", `
+			INSERT INTO clue_edges (from_clue_id, to_clue_id)
+			SELECT nf.id, nt.id
+			FROM clue_edges e
+			JOIN clues oldf ON oldf.id = e.from_clue_id
+			JOIN clues oldt ON oldt.id = e.to_clue_id
+			JOIN clues nf ON nf.event_id = $2 AND nf.pathway = oldf.pathway AND nf.index_num = oldf.index_num
+			JOIN clues nt ON nt.event_id = $2 AND nt.pathway = oldt.pathway AND nt.index_num = oldt.index_num
+			WHERE oldf.event_id = $1
+		`},
+	}
+	if includeGroups {
+		steps = append(steps, copyStep{"groups", `
+			INSERT INTO groups (event_id, name, pathway, password, members)
+			SELECT $2, name, pathway, password, members
+			FROM groups WHERE event_id = $1
+			ORDER BY id
+		`})
+	}
+
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, sourceID, id); err != nil {
+			return 0, fmt.Errorf("copy %s: %w", step.what, err)
+		}
+	}
+
+	// Signed codes are tied to the source event's secret
+	secret, err := setQRSecret(ctx, tx, id, false)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := signQRCodes(ctx, tx, id, secret, true); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *EventService) RenameEvent(ctx context.Context, id int, name string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE events SET name = $2 WHERE id = $1`, id, name)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrEventExists
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrEventNotFound
+	}
+	return nil
+}
+
+// SetArchived archives or restores an event. Archiving ends a running game,
+// closes any pause and cancels a scheduled start; groups of archived events
+// cannot log in.
+func (s *EventService) SetArchived(ctx context.Context, id int, archived bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE events SET archived = $2 WHERE id = $1`, id, archived)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrEventNotFound
+	}
+
+	if archived {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE game_settings
+			SET game_ended = game_started, scheduled_start = NULL
+			WHERE event_id = $1
+		`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE game_pauses SET resumed_at = $2 WHERE event_id = $1 AND resumed_at IS NULL
+		`, id, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -6,8 +6,45 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// gameStateError returns the error players get while the game does not
+// accept submissions, or nil while it is running.
+func gameStateError(started, ended, paused bool) error {
+	switch {
+	case !started:
+		return ErrGameNotStarted
+	case ended:
+		return ErrGameAlreadyEnded
+	case paused:
+		return ErrGamePaused
+	}
+	return nil
+}
+
+// checkGameActive fails unless the event's game is running. Run it inside
+// the transaction that makes the player's change so the state cannot flip
+// between the check and the write.
+func checkGameActive(ctx context.Context, q queryRower, eventID int) error {
+	var started, ended, paused bool
+	err := q.QueryRowContext(ctx, `
+		SELECT game_started, game_ended,
+		       EXISTS (SELECT 1 FROM game_pauses WHERE event_id = $1 AND resumed_at IS NULL)
+		FROM game_settings
+		WHERE event_id = $1
+		FOR SHARE
+	`, eventID).Scan(&started, &ended, &paused)
+	if err == sql.ErrNoRows {
+		return ErrNoSettingsRow
+	}
+	if err != nil {
+		return fmt.Errorf("query game state: %w", err)
+	}
+	return gameStateError(started, ended, paused)
+}
+
 type GameService struct {
 	db *sql.DB
 }
@@ -16,13 +53,21 @@ func NewGameService(db *sql.DB) *GameService {
 	return &GameService{db: db}
 }
 
-func (s *GameService) StartGame(ctx context.Context) error {
+// StartGame starts the event's game and deals out the clue orders of
+// shuffled pathways.
+func (s *GameService) StartGame(ctx context.Context, eventID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// Try to start the game only if it hasn't already started
-	res, err := s.db.ExecContext(ctx, `
+	res, err := tx.ExecContext(ctx, `
 		UPDATE game_settings
-		SET game_started = TRUE, start_time = $1
-		WHERE id = 1 AND game_started = FALSE
-	`, time.Now().UTC())
+		SET game_started = TRUE, start_time = $2, scheduled_start = NULL
+		WHERE event_id = $1 AND game_started = FALSE
+	`, eventID, time.Now().UTC())
 	if err != nil {
 		return err
 	}
@@ -36,10 +81,14 @@ func (s *GameService) StartGame(ctx context.Context) error {
 		return ErrGameAlreadyStarted
 	}
 
-	return nil
+	if err := assignClueOrders(ctx, tx, eventID, 0); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (s *GameService) EndGame(ctx context.Context) error {
+func (s *GameService) EndGame(ctx context.Context, eventID int) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -50,9 +99,9 @@ func (s *GameService) EndGame(ctx context.Context) error {
 	err = tx.QueryRowContext(ctx, `
         SELECT game_started, game_ended 
         FROM game_settings 
-        WHERE id = 1 
+        WHERE event_id = $1 
         FOR UPDATE
-    `).Scan(&gameStarted, &gameEnded)
+    `, eventID).Scan(&gameStarted, &gameEnded)
 
 	if err != nil {
 		return err
@@ -66,8 +115,16 @@ func (s *GameService) EndGame(ctx context.Context) error {
 	}
 
 	_, err = tx.ExecContext(ctx, `
-        UPDATE game_settings SET game_ended = TRUE WHERE id = 1
-    `)
+        UPDATE game_settings SET game_ended = TRUE WHERE event_id = $1
+    `, eventID)
+	if err != nil {
+		return err
+	}
+
+	// Ending a paused game closes the pause
+	_, err = tx.ExecContext(ctx, `
+		UPDATE game_pauses SET resumed_at = $2 WHERE event_id = $1 AND resumed_at IS NULL
+	`, eventID, time.Now().UTC())
 	if err != nil {
 		return err
 	}
@@ -75,8 +132,8 @@ func (s *GameService) EndGame(ctx context.Context) error {
 	return tx.Commit()
 }
 
-// In GameService
-func (s *GameService) ClearAllState(ctx context.Context) error {
+// ClearAllState resets the event's game and the progress of its groups.
+func (s *GameService) ClearAllState(ctx context.Context, eventID int) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -95,7 +152,8 @@ func (s *GameService) ClearAllState(ctx context.Context) error {
 		SET game_started = FALSE,
 		    game_ended = FALSE,
 		    start_time = NULL
-	`)
+		WHERE event_id = $1
+	`, eventID)
 	if err != nil {
 		return err
 	}
@@ -104,9 +162,34 @@ func (s *GameService) ClearAllState(ctx context.Context) error {
 	_, err = tx.ExecContext(ctx, `
 		UPDATE groups
 		SET current_clue_idx = 0,
+		    current_clue_id = NULL,
 		    completed = FALSE,
-		    end_time = NULL
-	`)
+		    end_time = NULL,
+		    penalty_seconds = 0,
+		    locked_until = NULL
+		WHERE event_id = $1
+	`, eventID)
+	if err != nil {
+		return err
+	}
+
+	// Forget revealed hints; their penalties were reset above
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM hint_reveals WHERE group_id IN (SELECT id FROM groups WHERE event_id = $1)
+	`, eventID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM game_pauses WHERE event_id = $1`, eventID)
+	if err != nil {
+		return err
+	}
+
+	// Clue orders are dealt out again at the next start
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM group_clue_order WHERE group_id IN (SELECT id FROM groups WHERE event_id = $1)
+	`, eventID)
 	if err != nil {
 		return err
 	}
@@ -115,15 +198,32 @@ func (s *GameService) ClearAllState(ctx context.Context) error {
 	return tx.Commit()
 }
 
-func (s *GameService) GetGameStatus(ctx context.Context) (*models.GameSettings, error) {
+func (s *GameService) GetGameStatus(ctx context.Context, eventID int) (*models.GameSettings, error) {
 	var settings models.GameSettings
 	var startTime sql.NullTime
 
 	err := s.db.QueryRowContext(ctx, `
-        SELECT id, total_clues, start_time, game_started, game_ended
+        SELECT event_id, start_time, game_started, game_ended,
+               wrong_scan_penalty_after, wrong_scan_penalty_seconds, lockout_after, lockout_seconds,
+               scheduled_start, max_duration_seconds,
+               (SELECT paused_at FROM game_pauses WHERE event_id = $1 AND resumed_at IS NULL),
+               COALESCE((
+                   SELECT EXTRACT(EPOCH FROM SUM(resumed_at - paused_at))::INTEGER
+                   FROM game_pauses
+                   WHERE event_id = $1 AND resumed_at IS NOT NULL
+               ), 0)
         FROM game_settings
-        WHERE id = 1
-    `).Scan(&settings.ID, &settings.TotalClues, &startTime, &settings.GameStarted, &settings.GameEnded)
+        WHERE event_id = $1
+    `, eventID).Scan(
+		&settings.EventID, &startTime, &settings.GameStarted, &settings.GameEnded,
+		&settings.ScanRules.PenaltyAfter, &settings.ScanRules.PenaltySeconds,
+		&settings.ScanRules.LockoutAfter, &settings.ScanRules.LockoutSeconds,
+		&settings.ScheduledStart, &settings.MaxDurationSeconds,
+		&settings.PausedAt, &settings.PausedSeconds,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoSettingsRow
+	}
 	if err != nil {
 		return nil, fmt.Errorf("GetGameStatus query failed: %w", err)
 	}
@@ -136,12 +236,171 @@ func (s *GameService) GetGameStatus(ctx context.Context) (*models.GameSettings,
 	return &settings, nil
 }
 
-func (s *GameService) GetTotalClues(ctx context.Context) (int, error) {
-	var totalClues int
-	err := s.db.QueryRowContext(ctx, `SELECT total_clues FROM game_settings WHERE id=1`).Scan(&totalClues)
+func (s *GameService) UpdateScanRules(ctx context.Context, eventID int, rules models.ScanRules) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE game_settings
+		SET wrong_scan_penalty_after = $1,
+		    wrong_scan_penalty_seconds = $2,
+		    lockout_after = $3,
+		    lockout_seconds = $4
+		WHERE event_id = $5
+	`, rules.PenaltyAfter, rules.PenaltySeconds, rules.LockoutAfter, rules.LockoutSeconds, eventID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoSettingsRow
+	}
+	return nil
+}
+
+// UpdateSchedule sets the scheduled start time and the maximum game duration.
+// Either may be nil to clear it. A start time can only be scheduled while the
+// game has not started.
+func (s *GameService) UpdateSchedule(ctx context.Context, eventID int, scheduledStart *time.Time, maxDurationSeconds *int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var gameStarted bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT game_started FROM game_settings WHERE event_id = $1 FOR UPDATE
+	`, eventID).Scan(&gameStarted)
+	if err == sql.ErrNoRows {
+		return ErrNoSettingsRow
+	}
+	if err != nil {
+		return err
+	}
+	if gameStarted && scheduledStart != nil {
+		return ErrGameAlreadyStarted
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE game_settings
+		SET scheduled_start = $2, max_duration_seconds = $3
+		WHERE event_id = $1
+	`, eventID, scheduledStart, maxDurationSeconds)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PauseGame freezes the game clock. Players cannot scan or reveal hints
+// until ResumeGame is called.
+func (s *GameService) PauseGame(ctx context.Context, eventID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		// default to 1 if no value exists
-		totalClues = 1
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkGameActive(ctx, tx, eventID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO game_pauses (event_id, paused_at) VALUES ($1, $2)
+	`, eventID, time.Now().UTC())
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		// Paused concurrently
+		return ErrGamePaused
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResumeGame closes the pause in progress. Scan lockouts that were running
+// when the game was paused are extended by the length of the pause.
+func (s *GameService) ResumeGame(ctx context.Context, eventID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the settings row so pausing and resuming are serialized
+	var gameEnded bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT game_ended FROM game_settings WHERE event_id = $1 FOR UPDATE
+	`, eventID).Scan(&gameEnded)
+	if err == sql.ErrNoRows {
+		return ErrNoSettingsRow
+	}
+	if err != nil {
+		return err
+	}
+	if gameEnded {
+		return ErrGameAlreadyEnded
+	}
+
+	var pausedAt, resumedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		UPDATE game_pauses SET resumed_at = $2
+		WHERE event_id = $1 AND resumed_at IS NULL
+		RETURNING paused_at, resumed_at
+	`, eventID, time.Now().UTC()).Scan(&pausedAt, &resumedAt)
+	if err == sql.ErrNoRows {
+		return ErrGameNotPaused
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE groups
+		SET locked_until = locked_until + ($2::timestamptz - $1::timestamptz)
+		WHERE event_id = $3 AND locked_until > $1
+	`, pausedAt, resumedAt, eventID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPauses returns every pause of the event's game, oldest first.
+func (s *GameService) GetPauses(ctx context.Context, eventID int) ([]models.GamePause, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT paused_at, resumed_at FROM game_pauses WHERE event_id = $1 ORDER BY paused_at
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []models.GamePause
+	for rows.Next() {
+		var p models.GamePause
+		if err := rows.Scan(&p.PausedAt, &p.ResumedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pause: %w", err)
+		}
+		pauses = append(pauses, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pauses: %w", err)
+	}
+	return pauses, nil
+}
+
+// CheckGameActive returns ErrGameNotStarted, ErrGameAlreadyEnded or
+// ErrGamePaused while players may not make progress, and nil otherwise.
+func (s *GameService) CheckGameActive(ctx context.Context, eventID int) error {
+	settings, err := s.GetGameStatus(ctx, eventID)
+	if err != nil {
+		return err
 	}
-	return totalClues, nil
+	return gameStateError(settings.GameStarted, settings.GameEnded, settings.Paused())
 }
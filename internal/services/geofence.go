@@ -0,0 +1,70 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"fmt"
+	"math"
+)
+
+const earthRadiusMeters = 6371000
+
+// ScanLocation is the device location a browser reported with a submission.
+type ScanLocation struct {
+	Latitude  float64
+	Longitude float64
+	// AccuracyMeters is the reported accuracy radius, when known.
+	AccuracyMeters *float64
+}
+
+// GeofenceError rejects an answer submitted away from its clue's geofence.
+// DistanceMeters is nil when no location was reported.
+type GeofenceError struct {
+	DistanceMeters *float64
+}
+
+func (e *GeofenceError) Error() string {
+	if e.DistanceMeters == nil {
+		return "answer submitted without a location"
+	}
+	return fmt.Sprintf("answer submitted %.0f m from the checkpoint", *e.DistanceMeters)
+}
+
+func (e *GeofenceError) Unwrap() error {
+	return ErrOutsideGeofence
+}
+
+// geofenceCheck is the outcome of checking a submission against the
+// geofence of the clue it solves.
+type geofenceCheck struct {
+	DistanceMeters *float64
+	Outside        bool
+	// Rejected is set when the submission is outside a geofence that turns
+	// such submissions away rather than only flagging them.
+	Rejected bool
+}
+
+// distanceMeters returns the great-circle distance between two points.
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// checkGeofence measures how far loc is from the clue's geofence. Clues
+// without a geofence accept any location; submissions without a location
+// are outside every geofence.
+func checkGeofence(clue *models.Clue, loc *ScanLocation) geofenceCheck {
+	fence := clue.Geofence
+	if fence == nil {
+		return geofenceCheck{}
+	}
+	if loc == nil {
+		return geofenceCheck{Outside: true, Rejected: fence.Reject}
+	}
+	d := distanceMeters(fence.Latitude, fence.Longitude, loc.Latitude, loc.Longitude)
+	outside := d > float64(fence.RadiusMeters)
+	return geofenceCheck{DistanceMeters: &d, Outside: outside, Rejected: outside && fence.Reject}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want, tolerance        float64
+	}{
+		{"same point", 51.5074, -0.1278, 51.5074, -0.1278, 0, 0.001},
+		// One degree of latitude is 1/360 of a great circle
+		{"one degree north", 10, 20, 11, 20, 2 * math.Pi * earthRadiusMeters / 360, 0.01},
+		{"one degree east at the equator", 0, 0, 0, 1, 2 * math.Pi * earthRadiusMeters / 360, 0.01},
+		{"across the date line", 0, 179.5, 0, -179.5, 2 * math.Pi * earthRadiusMeters / 360, 0.01},
+		{"antipodes", 0, 0, 0, 180, math.Pi * earthRadiusMeters, 0.01},
+		{"Paris to London", 48.8566, 2.3522, 51.5074, -0.1278, 343_560, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distanceMeters(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("distanceMeters = %.2f, want %.2f ± %.2f", got, tt.want, tt.tolerance)
+			}
+			if back := distanceMeters(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(back-got) > 0.001 {
+				t.Errorf("distance back is %.2f, not %.2f", back, got)
+			}
+		})
+	}
+}
+
+func TestCheckGeofence(t *testing.T) {
+	// About 111 m per 0.001 degrees of latitude
+	const lat, lng = 52.0, 4.0
+	fence := func(radius int, reject bool) *models.Clue {
+		return &models.Clue{Geofence: &models.Geofence{Latitude: lat, Longitude: lng, RadiusMeters: radius, Reject: reject}}
+	}
+	at := func(dLat float64) *ScanLocation {
+		return &ScanLocation{Latitude: lat + dLat, Longitude: lng}
+	}
+
+	tests := []struct {
+		name     string
+		clue     *models.Clue
+		loc      *ScanLocation
+		distance bool
+		outside  bool
+		rejected bool
+	}{
+		{"no geofence", &models.Clue{}, at(1), false, false, false},
+		{"no geofence or location", &models.Clue{}, nil, false, false, false},
+		{"at the centre", fence(50, false), at(0), true, false, false},
+		{"inside", fence(150, false), at(0.001), true, false, false},
+		{"outside", fence(100, false), at(0.001), true, true, false},
+		{"outside a rejecting geofence", fence(100, true), at(0.001), true, true, true},
+		{"inside a rejecting geofence", fence(150, true), at(0.001), true, false, false},
+		{"no location", fence(100, false), nil, false, true, false},
+		{"no location for a rejecting geofence", fence(100, true), nil, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkGeofence(tt.clue, tt.loc)
+			if (got.DistanceMeters != nil) != tt.distance {
+				t.Errorf("DistanceMeters = %v, want set %v", got.DistanceMeters, tt.distance)
+			}
+			if got.Outside != tt.outside {
+				t.Errorf("Outside = %v, want %v", got.Outside, tt.outside)
+			}
+			if got.Rejected != tt.rejected {
+				t.Errorf("Rejected = %v, want %v", got.Rejected, tt.rejected)
+			}
+		})
+	}
+
+	got := checkGeofence(fence(100, false), at(0.001))
+	if d := *got.DistanceMeters; math.Abs(d-111.19) > 0.1 {
+		t.Errorf("DistanceMeters = %.2f, want about 111.19", d)
+	}
+}
+
+func TestGeofenceError(t *testing.T) {
+	d := 123.4
+	if err := error(&GeofenceError{DistanceMeters: &d}); !errors.Is(err, ErrOutsideGeofence) || err.Error() != "answer submitted 123 m from the checkpoint" {
+		t.Errorf("GeofenceError with a distance = %q", err)
+	}
+	if err := error(&GeofenceError{}); !errors.Is(err, ErrOutsideGeofence) || err.Error() != "answer submitted without a location" {
+		t.Errorf("GeofenceError without a location = %q", err)
+	}
+}
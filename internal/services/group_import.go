@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"cyberhunt/internal/utils"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// NewGroup is a group to be created by ImportGroups. Password is the
+// plaintext password the group logs in with.
+type NewGroup struct {
+	Name     string
+	Pathway  string
+	Password string
+	Members  []string
+}
+
+// ListGroups returns the groups of an event ordered by pathway and name,
+// without their passwords.
+func (s *GroupService) ListGroups(ctx context.Context, eventID int) ([]*models.Group, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, event_id, name, pathway, members
+		FROM groups
+		WHERE event_id = $1
+		ORDER BY pathway, name
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*models.Group{}
+	for rows.Next() {
+		var g models.Group
+		if err := rows.Scan(&g.ID, &g.EventID, &g.Name, &g.Pathway, pq.Array(&g.Members)); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, &g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating groups: %w", err)
+	}
+	return groups, nil
+}
+
+// ImportGroups creates all the groups or none of them. Groups imported while
+// the game is under way get their clue order straight away.
+func (s *GroupService) ImportGroups(ctx context.Context, eventID int, groups []NewGroup) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ids, err := insertGroups(ctx, tx, eventID, groups)
+	if err != nil {
+		return err
+	}
+
+	var started bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT game_started FROM game_settings WHERE event_id = $1
+	`, eventID).Scan(&started)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if started {
+		for _, id := range ids {
+			if err := assignClueOrders(ctx, tx, eventID, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertGroups creates groups as part of the caller's transaction and
+// returns their IDs in order.
+func insertGroups(ctx context.Context, tx *sql.Tx, eventID int, groups []NewGroup) ([]int, error) {
+	ids := make([]int, len(groups))
+	for i, g := range groups {
+		hash, err := utils.HashPassword(g.Password)
+		if err != nil {
+			return nil, err
+		}
+		members := g.Members
+		if members == nil {
+			members = []string{}
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO groups (event_id, name, pathway, password, members)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, eventID, g.Name, g.Pathway, hash, pq.Array(members)).Scan(&ids[i])
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("group %q: %w", g.Name, ErrGroupExists)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("insert group %q: %w", g.Name, err)
+		}
+	}
+	return ids, nil
+}
+
+// ResetGroupPasswords gives every group of the event a new password, keyed
+// by group ID, and logs the groups out. Only hashes are stored, so this is
+// the only time they can be read back.
+func (s *GroupService) ResetGroupPasswords(ctx context.Context, eventID int, passwords map[int]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, password := range passwords {
+		hash, err := utils.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE groups SET password = $3 WHERE id = $1 AND event_id = $2
+		`, id, eventID, hash)
+		if err != nil {
+			return fmt.Errorf("reset password for group %d: %w", id, err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM sessions s USING groups g
+			WHERE s.group_id = g.id AND g.id = $1 AND g.event_id = $2
+		`, id, eventID)
+		if err != nil {
+			return fmt.Errorf("revoke sessions of group %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
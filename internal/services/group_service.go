@@ -2,8 +2,8 @@ package services
 
 import (
 	"context"
-	"crypto/subtle"
 	"cyberhunt/internal/models"
+	"cyberhunt/internal/utils"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -19,26 +19,69 @@ func NewGroupService(db *sql.DB) *GroupService {
 	return &GroupService{db: db}
 }
 
-func (s *GroupService) GetGroupByNameAndPassword(ctx context.Context, name, password string) (*models.Group, error) {
+// GetGroupByNameAndPassword looks the group up by name within the event and
+// verifies the password against the stored hash. Groups still holding a
+// plaintext password are upgraded to a hash on their first successful login.
+func (s *GroupService) GetGroupByNameAndPassword(ctx context.Context, eventID int, name, password string) (*models.Group, error) {
 	var group models.Group
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, pathway, current_clue_idx, completed, end_time, password
-		FROM groups WHERE name = $1 AND password = $2
-	`, name, password).Scan(
-		&group.ID, &group.Name, &group.Pathway, &group.CurrentClueIdx,
+		SELECT id, event_id, name, pathway, current_clue_idx, completed, end_time, password
+		FROM groups WHERE event_id = $1 AND name = $2
+	`, eventID, name).Scan(
+		&group.ID, &group.EventID, &group.Name, &group.Pathway, &group.CurrentClueIdx,
 		&group.Completed, &group.EndTime, &group.Password,
 	)
+	if err == sql.ErrNoRows {
+		utils.RejectPassword(password)
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	ok, needsRehash := utils.CheckPassword(group.Password, password)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		hash, err := utils.HashPassword(password)
+		if err != nil {
+			return nil, fmt.Errorf("hash password for group %d: %w", group.ID, err)
+		}
+		// Only replace the value we verified, in case it changed meanwhile
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE groups SET password = $3 WHERE id = $1 AND password = $2
+		`, group.ID, group.Password, hash)
+		if err != nil {
+			return nil, fmt.Errorf("upgrade password for group %d: %w", group.ID, err)
+		}
+		group.Password = hash
+	}
+
 	return &group, nil
 }
 
-func (s *GroupService) AddGroup(ctx context.Context, name, pathway, password string) error {
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO groups (name, pathway, password)
-        VALUES ($1, $2, $3)
-    `, name, pathway, password)
+// AddGroup creates a group. Groups added while the game is under way get
+// their clue order straight away.
+func (s *GroupService) AddGroup(ctx context.Context, eventID int, name, pathway, password string) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO groups (event_id, name, pathway, password)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `, eventID, name, pathway, hash).Scan(&id)
 
 	if err != nil {
 		// Check for Postgres unique violation
@@ -48,11 +91,24 @@ func (s *GroupService) AddGroup(ctx context.Context, name, pathway, password str
 		return err
 	}
 
-	return nil
+	var started bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT game_started FROM game_settings WHERE event_id = $1
+	`, eventID).Scan(&started)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if started {
+		if err := assignClueOrders(ctx, tx, eventID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (s *GroupService) DeleteGroup(ctx context.Context, id int) error {
-	res, err := s.db.ExecContext(ctx, "DELETE FROM groups WHERE id = $1", id)
+func (s *GroupService) DeleteGroup(ctx context.Context, eventID, id int) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM groups WHERE id = $1 AND event_id = $2", id, eventID)
 	if err != nil {
 		return err
 	}
@@ -72,12 +128,14 @@ func (s *GroupService) DeleteGroup(ctx context.Context, id int) error {
 func (s *GroupService) GetGroupByID(ctx context.Context, id int) (*models.Group, error) {
 	var group models.Group
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, pathway, current_clue_idx, completed, end_time
+		SELECT id, event_id, name, pathway, current_clue_idx, completed, end_time, penalty_seconds, locked_until,
+		       current_clue_id
 		FROM groups
 		WHERE id = $1
 	`, id).Scan(
-		&group.ID, &group.Name, &group.Pathway, &group.CurrentClueIdx,
-		&group.Completed, &group.EndTime,
+		&group.ID, &group.EventID, &group.Name, &group.Pathway, &group.CurrentClueIdx,
+		&group.Completed, &group.EndTime, &group.PenaltySeconds, &group.LockedUntil,
+		&group.CurrentClueID,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("group %d not found", id) // clean error
@@ -88,52 +146,29 @@ func (s *GroupService) GetGroupByID(ctx context.Context, id int) (*models.Group,
 	return &group, nil
 }
 
-func (s *GroupService) UpdateGroupProgress(ctx context.Context, id, expectedClueIdx int, totalClues int) error {
-	// Move to the *next* clue only if the group is still at expectedClueIdx
-	// This prevents races / duplicate scans.
-	query := `
+func (s *GroupService) ResetGroups(ctx context.Context, eventID int) error {
+	_, err := s.db.ExecContext(ctx, `
 		UPDATE groups
-		SET 
-			current_clue_idx = current_clue_idx + 1,
-			completed = (current_clue_idx + 1) >= $2,
-			end_time = CASE 
-				WHEN (current_clue_idx + 1) >= $2 AND end_time IS NULL 
-				THEN NOW() AT TIME ZONE 'UTC' 
-				ELSE end_time 
-			END
-		WHERE id = $1 AND current_clue_idx = $3 AND completed = FALSE
-	`
-	res, err := s.db.ExecContext(ctx, query, id, totalClues, expectedClueIdx)
+		SET current_clue_idx = 0, current_clue_id = NULL, completed = FALSE, end_time = NULL,
+		    penalty_seconds = 0, locked_until = NULL
+		WHERE event_id = $1
+	`, eventID)
 	if err != nil {
-		return fmt.Errorf("failed to update progress for group %d: %w", id, err)
-	}
-
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check progress update for group %d: %w", id, err)
-	}
-	if rows == 0 {
-		// Either wrong clue index, already completed, or race condition lost
-		return errors.New("progress not updated (stale or already completed)")
+		return err
 	}
-
-	return nil
-}
-
-func (s *GroupService) ResetGroups(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-		UPDATE groups
-		SET current_clue_idx = 0, completed = FALSE, end_time = NULL
-	`)
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM hint_reveals WHERE group_id IN (SELECT id FROM groups WHERE event_id = $1)
+	`, eventID)
 	return err
 }
 
-func (s *GroupService) GetGroupsForLeaderboard(ctx context.Context) ([]models.Group, error) {
+func (s *GroupService) GetGroupsForLeaderboard(ctx context.Context, eventID int) ([]models.Group, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, name, pathway, current_clue_idx, completed, end_time
 		FROM groups
+		WHERE event_id = $1
 		ORDER BY completed DESC, current_clue_idx DESC, end_time ASC
-	`)
+	`, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -158,32 +193,66 @@ func (s *GroupService) GetGroupsForLeaderboard(ctx context.Context) ([]models.Gr
 	return groups, nil
 }
 
-func (s *GroupService) GetLeaderboardData(ctx context.Context) (int, []models.Group, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+// GetLeaderboardData returns the effective length of every pathway of the
+// event keyed by name together with its ranked groups, read from a single
+// snapshot.
+func (s *GroupService) GetLeaderboardData(ctx context.Context, eventID int) (map[string]int, []models.Group, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
 	if err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 	defer tx.Rollback()
 
-	var totalClues int
-	err = tx.QueryRowContext(ctx, `
-        SELECT total_clues
-        FROM game_settings
-        WHERE id = 1
-    `).Scan(&totalClues)
-	if err == sql.ErrNoRows {
-		totalClues = 1
-	} else if err != nil {
-		return 0, nil, err
+	totalRows, err := tx.QueryContext(ctx, `
+        SELECT p.name, `+pathwayTotalCluesExpr+`
+        FROM pathways p
+        WHERE p.event_id = $1
+    `, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer totalRows.Close()
+
+	totals := make(map[string]int)
+	for totalRows.Next() {
+		var name string
+		var total int
+		if err := totalRows.Scan(&name, &total); err != nil {
+			return nil, nil, err
+		}
+		totals[name] = total
+	}
+	if err := totalRows.Err(); err != nil {
+		return nil, nil, err
+	}
+	totalRows.Close()
+
+	lengths, err := graphPathwayLengths(ctx, tx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, n := range lengths {
+		totals[name] = n
 	}
 
+	// Finishers are ranked by their end time less the time the game spent
+	// paused before it, plus penalties
 	rows, err := tx.QueryContext(ctx, `
-        SELECT id, name, pathway, current_clue_idx, completed, end_time
-        FROM groups
-        ORDER BY completed DESC, current_clue_idx DESC, end_time ASC, id ASC
-    `)
+        SELECT g.id, g.event_id, g.name, g.pathway, g.current_clue_idx, g.completed, g.end_time, g.penalty_seconds
+        FROM groups g
+        WHERE g.event_id = $1
+        ORDER BY g.completed DESC, g.current_clue_idx DESC,
+                 g.end_time
+                   - COALESCE((
+                       SELECT SUM(LEAST(COALESCE(p.resumed_at, g.end_time), g.end_time) - p.paused_at)
+                       FROM game_pauses p
+                       WHERE p.event_id = g.event_id AND p.paused_at < g.end_time
+                     ), INTERVAL '0')
+                   + make_interval(secs => g.penalty_seconds) ASC,
+                 g.id ASC
+    `, eventID)
 	if err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -192,10 +261,10 @@ func (s *GroupService) GetLeaderboardData(ctx context.Context) (int, []models.Gr
 		var g models.Group
 		var endTime sql.NullTime
 		if err := rows.Scan(
-			&g.ID, &g.Name, &g.Pathway, &g.CurrentClueIdx,
-			&g.Completed, &endTime,
+			&g.ID, &g.EventID, &g.Name, &g.Pathway, &g.CurrentClueIdx,
+			&g.Completed, &endTime, &g.PenaltySeconds,
 		); err != nil {
-			return 0, nil, err
+			return nil, nil, err
 		}
 		if endTime.Valid {
 			g.EndTime = &endTime.Time
@@ -203,22 +272,27 @@ func (s *GroupService) GetLeaderboardData(ctx context.Context) (int, []models.Gr
 		groups = append(groups, g)
 	}
 	if err := rows.Err(); err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 
-	return totalClues, groups, nil
+	return totals, groups, nil
 }
 
-// In GroupService or a new "GameService"
-func (s *GroupService) ScanAndUpdateProgress(
+// SubmitAnswer validates a scanned code or typed answer against the group's
+// current clue, according to the clue's answer type, and advances the group
+// on a match. A correct answer from outside the solved clue's geofence is
+// flagged, or rejected with a GeofenceError when the geofence says so. Every
+// submission, accepted or not, is recorded in scan_events within the same
+// transaction.
+func (s *GroupService) SubmitAnswer(
 	ctx context.Context,
 	groupID int,
-	scannedCode string,
-	totalClues int,
+	answer string,
+	meta ScanMetadata,
 ) (*models.Group, error) {
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
 	if err != nil {
@@ -226,42 +300,132 @@ func (s *GroupService) ScanAndUpdateProgress(
 	}
 	defer func() { _ = tx.Rollback() }() // rollback if not committed
 
-	// 1. Lock the group row
+	// 1. Check the group's game accepts submissions. Settings are read
+	// before the group row is locked, matching the lock order of
+	// ClearAllState. A group never moves between events.
+	var eventID int
+	err = tx.QueryRowContext(ctx, `SELECT event_id FROM groups WHERE id = $1`, groupID).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group %d not found", groupID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query group event: %w", err)
+	}
+	stateErr := checkGameActive(ctx, tx, eventID)
+	if errors.Is(stateErr, ErrNoSettingsRow) {
+		return nil, stateErr
+	}
+
+	// 2. Lock the group row
 	var g models.Group
+	var locked bool
 	err = tx.QueryRowContext(ctx, `
-        SELECT id, name, pathway, current_clue_idx, completed, end_time
+        SELECT id, event_id, name, pathway, current_clue_idx, completed, end_time,
+               penalty_seconds, locked_until, COALESCE(locked_until > NOW(), FALSE), current_clue_id
         FROM groups
         WHERE id = $1
         FOR UPDATE
-    `, groupID).Scan(&g.ID, &g.Name, &g.Pathway, &g.CurrentClueIdx, &g.Completed, &g.EndTime)
+    `, groupID).Scan(
+		&g.ID, &g.EventID, &g.Name, &g.Pathway, &g.CurrentClueIdx, &g.Completed, &g.EndTime,
+		&g.PenaltySeconds, &g.LockedUntil, &locked, &g.CurrentClueID,
+	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("group %d not found", groupID)
 		}
 		return nil, fmt.Errorf("query group: %w", err)
 	}
+	if stateErr != nil {
+		if err := insertScanEvent(ctx, tx, g.ID, nil, g.CurrentClueIdx, answer, ScanResultGameClosed, meta, geofenceCheck{}); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, stateErr
+	}
+	if locked {
+		if err := insertScanEvent(ctx, tx, g.ID, nil, g.CurrentClueIdx, answer, ScanResultLocked, meta, geofenceCheck{}); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, ErrGroupLocked
+	}
 	if g.Completed {
-		return &g, fmt.Errorf("group already completed")
+		if err := insertScanEvent(ctx, tx, g.ID, nil, g.CurrentClueIdx, answer, ScanResultAlreadyCompleted, meta, geofenceCheck{}); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, ErrGroupCompleted
 	}
 
-	// 2. Load expected clue
-	var expectedCode string
+	// 3. Load the pathway length and the expected clue
+	var totalClues int
+	mode := models.PathwayModeLinear
 	err = tx.QueryRowContext(ctx, `
-        SELECT qrcode FROM clues WHERE pathway = $1 AND index_num = $2
-    `, g.Pathway, g.CurrentClueIdx).Scan(&expectedCode)
+        SELECT `+pathwayTotalCluesExpr+`, p.mode FROM pathways p WHERE p.event_id = $1 AND p.name = $2
+    `, g.EventID, g.Pathway).Scan(&totalClues, &mode)
+	if err == sql.ErrNoRows {
+		totalClues = 1
+	} else if err != nil {
+		return nil, fmt.Errorf("query pathway length: %w", err)
+	}
+
+	clue, err := currentClue(ctx, tx, &g)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("clue not found for pathway=%s index=%d", g.Pathway, g.CurrentClueIdx)
+		return nil, err
+	}
+	clueID := clue.ID
+	qrSecret, err := eventQRSecret(ctx, tx, g.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == models.PathwayModeGraph {
+		err := advanceOnGraph(ctx, tx, &g, clueID, answer, qrSecret, meta)
+		if err != nil && !errors.Is(err, ErrWrongAnswer) && !errors.Is(err, ErrOutsideGeofence) {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, err
+	}
+
+	// 4. Validate the answer
+	if !answerAccepted(clue, answer, qrSecret) {
+		if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultWrong, meta, geofenceCheck{}); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("query clue: %w", err)
+		penalty, err := applyWrongScanRules(ctx, tx, &g, clueID)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, &WrongAnswerError{PenaltySeconds: penalty}
 	}
 
-	// 3. Validate scanned QR
-	if subtle.ConstantTimeCompare([]byte(scannedCode), []byte(expectedCode)) != 1 {
-		return nil, fmt.Errorf("invalid QR code")
+	fence := checkGeofence(clue, meta.Location)
+	if fence.Rejected {
+		if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultOutsideGeofence, meta, fence); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return &g, &GeofenceError{DistanceMeters: fence.DistanceMeters}
+	}
+	if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultCorrect, meta, fence); err != nil {
+		return nil, err
 	}
 
-	// 4. Update progress atomically and fetch new state
+	// 5. Update progress atomically and fetch new state
 	err = tx.QueryRowContext(ctx, `
         UPDATE groups
         SET current_clue_idx = current_clue_idx + 1,
@@ -287,3 +451,121 @@ func (s *GroupService) ScanAndUpdateProgress(
 
 	return &g, nil
 }
+
+// advanceOnGraph moves a group on a graph pathway from clueID along the edge
+// whose target the answer solves, completing the group when it reaches a
+// finish clue. current_clue_idx follows the group's distance to the finish.
+// An answer no edge accepts is recorded as a wrong scan and a
+// WrongAnswerError is returned, and one rejected by the target's geofence returns a
+// GeofenceError; the caller commits either way.
+func advanceOnGraph(ctx context.Context, tx *sql.Tx, g *models.Group, clueID int, answer string, qrSecret []byte, meta ScanMetadata) error {
+	graph, err := loadClueGraph(ctx, tx, g.EventID, g.Pathway)
+	if err != nil {
+		return err
+	}
+
+	next := graph.match(clueID, answer, qrSecret)
+	if next == 0 {
+		if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultWrong, meta, geofenceCheck{}); err != nil {
+			return err
+		}
+		penalty, err := applyWrongScanRules(ctx, tx, g, clueID)
+		if err != nil {
+			return err
+		}
+		return &WrongAnswerError{PenaltySeconds: penalty}
+	}
+
+	fence := checkGeofence(graph.clues[next], meta.Location)
+	if fence.Rejected {
+		if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultOutsideGeofence, meta, fence); err != nil {
+			return err
+		}
+		return &GeofenceError{DistanceMeters: fence.DistanceMeters}
+	}
+	if err := insertScanEvent(ctx, tx, g.ID, &clueID, g.CurrentClueIdx, answer, ScanResultCorrect, meta, fence); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+        UPDATE groups
+        SET current_clue_id = $2,
+            current_clue_idx = $3,
+            completed = $4,
+            end_time = CASE
+                WHEN $4 AND end_time IS NULL THEN NOW() AT TIME ZONE 'UTC'
+                ELSE end_time
+            END
+        WHERE id = $1
+        RETURNING id, name, pathway, current_clue_idx, completed, end_time, current_clue_id
+    `, g.ID, next, graph.progress(next), graph.finish(next)).Scan(
+		&g.ID, &g.Name, &g.Pathway, &g.CurrentClueIdx, &g.Completed, &g.EndTime, &g.CurrentClueID,
+	)
+	if err != nil {
+		return fmt.Errorf("update group progress: %w", err)
+	}
+	return nil
+}
+
+// WrongAnswerError rejects a wrong submission. PenaltySeconds is the time
+// penalty it added, which is 0 while the group is within its free attempts.
+type WrongAnswerError struct {
+	PenaltySeconds int
+}
+
+func (e *WrongAnswerError) Error() string {
+	return ErrWrongAnswer.Error()
+}
+
+func (e *WrongAnswerError) Unwrap() error {
+	return ErrWrongAnswer
+}
+
+// applyWrongScanRules charges the configured time penalty and lockout once a
+// wrong submission for clueID has been recorded in the caller's transaction,
+// and returns the penalty charged. Only wrong submissions made since the
+// current game started are counted.
+func applyWrongScanRules(ctx context.Context, tx *sql.Tx, g *models.Group, clueID int) (int, error) {
+	var rules models.ScanRules
+	var wrong int
+	err := tx.QueryRowContext(ctx, `
+		SELECT s.wrong_scan_penalty_after, s.wrong_scan_penalty_seconds, s.lockout_after, s.lockout_seconds,
+		       (SELECT COUNT(*) FROM scan_events e
+		        WHERE e.group_id = $1 AND e.clue_id = $2 AND e.result = $3
+		          AND e.created_at >= COALESCE(s.start_time, '-infinity'))
+		FROM game_settings s
+		WHERE s.event_id = $4
+	`, g.ID, clueID, ScanResultWrong, g.EventID).Scan(
+		&rules.PenaltyAfter, &rules.PenaltySeconds, &rules.LockoutAfter, &rules.LockoutSeconds, &wrong,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("query scan rules: %w", err)
+	}
+
+	penalty := 0
+	if rules.PenaltySeconds > 0 && wrong > rules.PenaltyAfter {
+		penalty = rules.PenaltySeconds
+	}
+	lockFor := 0
+	if rules.LockoutAfter > 0 && rules.LockoutSeconds > 0 && wrong%rules.LockoutAfter == 0 {
+		lockFor = rules.LockoutSeconds
+	}
+	if penalty == 0 && lockFor == 0 {
+		return 0, nil
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE groups
+		SET penalty_seconds = penalty_seconds + $2,
+		    locked_until = CASE
+		        WHEN $3::int > 0 THEN NOW() + make_interval(secs => $3::int)
+		        ELSE locked_until
+		    END
+		WHERE id = $1
+		RETURNING penalty_seconds, locked_until
+	`, g.ID, penalty, lockFor).Scan(&g.PenaltySeconds, &g.LockedUntil)
+	if err != nil {
+		return 0, fmt.Errorf("apply wrong scan rules: %w", err)
+	}
+	return penalty, nil
+}
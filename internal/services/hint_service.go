@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type HintService struct {
+	db *sql.DB
+}
+
+func NewHintService(db *sql.DB) *HintService {
+	return &HintService{db: db}
+}
+
+func scanHints(rows *sql.Rows) ([]*models.Hint, error) {
+	defer rows.Close()
+
+	hints := []*models.Hint{}
+	for rows.Next() {
+		var h models.Hint
+		if err := rows.Scan(&h.ID, &h.ClueID, &h.Position, &h.Content, &h.PenaltySeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan hint: %w", err)
+		}
+		hints = append(hints, &h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hints: %w", err)
+	}
+	return hints, nil
+}
+
+// GetHintsForClue returns the hints of a clue of the event. It returns
+// ErrClueNotFound if the event has no such clue.
+func (s *HintService) GetHintsForClue(ctx context.Context, eventID, clueID int) ([]*models.Hint, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM clues WHERE id = $1 AND event_id = $2)
+	`, clueID, eventID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check clue %d: %w", clueID, err)
+	}
+	if !exists {
+		return nil, ErrClueNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, clue_id, position, content, penalty_seconds
+		FROM clue_hints
+		WHERE clue_id = $1
+		ORDER BY position
+	`, clueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hints for clue %d: %w", clueID, err)
+	}
+	return scanHints(rows)
+}
+
+// GetHint returns a single hint of the event's clues by ID.
+func (s *HintService) GetHint(ctx context.Context, eventID, id int) (*models.Hint, error) {
+	var h models.Hint
+	err := s.db.QueryRowContext(ctx, `
+		SELECT h.id, h.clue_id, h.position, h.content, h.penalty_seconds
+		FROM clue_hints h
+		JOIN clues c ON c.id = h.clue_id AND c.event_id = $2
+		WHERE h.id = $1
+	`, id, eventID).Scan(&h.ID, &h.ClueID, &h.Position, &h.Content, &h.PenaltySeconds)
+	if err == sql.ErrNoRows {
+		return nil, ErrHintNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hint %d: %w", id, err)
+	}
+	return &h, nil
+}
+
+// GetRevealedHints returns the hints of a clue the group has already revealed.
+func (s *HintService) GetRevealedHints(ctx context.Context, groupID, clueID int) ([]*models.Hint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT h.id, h.clue_id, h.position, h.content, r.penalty_seconds
+		FROM clue_hints h
+		JOIN hint_reveals r ON r.hint_id = h.id AND r.group_id = $1
+		WHERE h.clue_id = $2
+		ORDER BY h.position
+	`, groupID, clueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revealed hints: %w", err)
+	}
+	return scanHints(rows)
+}
+
+// CountHints returns how many hints a clue has in total.
+func (s *HintService) CountHints(ctx context.Context, clueID int) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clue_hints WHERE clue_id = $1`, clueID).Scan(&n)
+	return n, err
+}
+
+// AddHint adds a hint to a clue of the event. It returns ErrClueNotFound if
+// the event has no such clue.
+func (s *HintService) AddHint(ctx context.Context, eventID, clueID, position int, content string, penaltySeconds int) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO clue_hints (clue_id, position, content, penalty_seconds)
+		SELECT id, $2, $3, $4 FROM clues WHERE id = $1 AND event_id = $5
+	`, clueID, position, content, penaltySeconds, eventID)
+	if err != nil {
+		return hintWriteError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrClueNotFound
+	}
+	return nil
+}
+
+// UpdateHint changes a hint of the event's clues. It returns ErrHintNotFound
+// if the event has no such hint.
+func (s *HintService) UpdateHint(ctx context.Context, eventID, id, position int, content string, penaltySeconds int) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE clue_hints h
+		SET position = $2, content = $3, penalty_seconds = $4
+		FROM clues c
+		WHERE h.id = $1 AND c.id = h.clue_id AND c.event_id = $5
+	`, id, position, content, penaltySeconds, eventID)
+	if err != nil {
+		return hintWriteError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrHintNotFound
+	}
+	return nil
+}
+
+// DeleteHint removes a hint of the event's clues. It returns ErrHintNotFound
+// if the event has no such hint.
+func (s *HintService) DeleteHint(ctx context.Context, eventID, id int) error {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM clue_hints h
+		USING clues c
+		WHERE h.id = $1 AND c.id = h.clue_id AND c.event_id = $2
+	`, id, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to delete hint with id %d: %w", id, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrHintNotFound
+	}
+	return nil
+}
+
+func hintWriteError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return ErrHintExists
+		case "23503":
+			return ErrClueNotFound
+		}
+	}
+	return err
+}
+
+// RevealNextHint reveals the lowest-positioned hint of the group's current
+// clue that it has not seen yet and adds the hint's penalty to the group.
+func (s *HintService) RevealNextHint(ctx context.Context, groupID int) (*models.Hint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var eventID int
+	err = tx.QueryRowContext(ctx, `SELECT event_id FROM groups WHERE id = $1`, groupID).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group %d not found", groupID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query group event: %w", err)
+	}
+	if err := checkGameActive(ctx, tx, eventID); err != nil {
+		return nil, err
+	}
+
+	var g models.Group
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, event_id, pathway, current_clue_idx, completed, current_clue_id
+		FROM groups
+		WHERE id = $1
+		FOR UPDATE
+	`, groupID).Scan(&g.ID, &g.EventID, &g.Pathway, &g.CurrentClueIdx, &g.Completed, &g.CurrentClueID)
+	if err != nil {
+		return nil, fmt.Errorf("query group: %w", err)
+	}
+	if g.Completed {
+		return nil, ErrGroupCompleted
+	}
+
+	clue, err := currentClue(ctx, tx, &g)
+	if err != nil {
+		return nil, err
+	}
+
+	var h models.Hint
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, clue_id, position, content, penalty_seconds
+		FROM clue_hints h
+		WHERE clue_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM hint_reveals r WHERE r.hint_id = h.id AND r.group_id = $2)
+		ORDER BY position
+		LIMIT 1
+	`, clue.ID, g.ID).Scan(&h.ID, &h.ClueID, &h.Position, &h.Content, &h.PenaltySeconds)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoMoreHints
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query next hint: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO hint_reveals (group_id, hint_id, penalty_seconds)
+		VALUES ($1, $2, $3)
+	`, g.ID, h.ID, h.PenaltySeconds)
+	if err != nil {
+		return nil, fmt.Errorf("record hint reveal: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE groups SET penalty_seconds = penalty_seconds + $2 WHERE id = $1
+	`, g.ID, h.PenaltySeconds)
+	if err != nil {
+		return nil, fmt.Errorf("apply hint penalty: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return &h, nil
+}
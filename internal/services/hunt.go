@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Hunt is everything needed to set up an event: its rules, pathways, clues
+// and hints, and optionally its groups.
+type Hunt struct {
+	ScanRules          models.ScanRules
+	MaxDurationSeconds *int
+	Pathways           []HuntPathway
+	// Groups replace the event's groups on import unless nil. Exported groups
+	// have no password, as only hashes are stored.
+	Groups []NewGroup
+}
+
+type HuntPathway struct {
+	models.Pathway
+	Clues []HuntClue
+}
+
+type HuntClue struct {
+	models.Clue
+	Hints []models.Hint
+	// Next holds the index_num of every clue an edge from this one leads to.
+	Next []int
+}
+
+// ExportHunt reads the setup of an event.
+func (s *EventService) ExportHunt(ctx context.Context, eventID int) (*Hunt, error) {
+	var hunt Hunt
+	err := s.db.QueryRowContext(ctx, `
+		SELECT wrong_scan_penalty_after, wrong_scan_penalty_seconds, lockout_after, lockout_seconds, max_duration_seconds
+		FROM game_settings WHERE event_id = $1
+	`, eventID).Scan(
+		&hunt.ScanRules.PenaltyAfter, &hunt.ScanRules.PenaltySeconds,
+		&hunt.ScanRules.LockoutAfter, &hunt.ScanRules.LockoutSeconds, &hunt.MaxDurationSeconds,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoSettingsRow
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch game settings: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, color, description, active, total_clues, shuffle_clues, pin_first_clue, pin_last_clue, mode
+		FROM pathways WHERE event_id = $1
+		ORDER BY id
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pathways: %w", err)
+	}
+	pathways := map[string]int{}
+	for rows.Next() {
+		var p HuntPathway
+		if err := rows.Scan(
+			&p.Name, &p.Color, &p.Description, &p.Active, &p.TotalClues,
+			&p.ClueOrder.Shuffle, &p.ClueOrder.PinFirst, &p.ClueOrder.PinLast, &p.Mode,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pathway: %w", err)
+		}
+		pathways[p.Name] = len(hunt.Pathways)
+		hunt.Pathways = append(hunt.Pathways, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pathways: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT `+clueColumns+` FROM clues WHERE event_id = $1 ORDER BY pathway, index_num
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clues: %w", err)
+	}
+	clues := map[int]*HuntClue{}
+	for rows.Next() {
+		var clue models.Clue
+		if err := scanClue(rows, &clue); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan clue: %w", err)
+		}
+		i, ok := pathways[clue.Pathway]
+		if !ok {
+			continue
+		}
+		p := &hunt.Pathways[i]
+		p.Clues = append(p.Clues, HuntClue{Clue: clue})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clues: %w", err)
+	}
+	for i := range hunt.Pathways {
+		for j := range hunt.Pathways[i].Clues {
+			clue := &hunt.Pathways[i].Clues[j]
+			clues[clue.ID] = clue
+		}
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT h.clue_id, h.position, h.content, h.penalty_seconds
+		FROM clue_hints h
+		JOIN clues c ON c.id = h.clue_id
+		WHERE c.event_id = $1
+		ORDER BY h.clue_id, h.position
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hints: %w", err)
+	}
+	for rows.Next() {
+		var h models.Hint
+		if err := rows.Scan(&h.ClueID, &h.Position, &h.Content, &h.PenaltySeconds); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan hint: %w", err)
+		}
+		if clue, ok := clues[h.ClueID]; ok {
+			clue.Hints = append(clue.Hints, h)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hints: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT e.from_clue_id, t.index_num
+		FROM clue_edges e
+		JOIN clues f ON f.id = e.from_clue_id
+		JOIN clues t ON t.id = e.to_clue_id
+		WHERE f.event_id = $1 AND t.event_id = $1 AND t.pathway = f.pathway
+		ORDER BY e.from_clue_id, t.index_num
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clue edges: %w", err)
+	}
+	for rows.Next() {
+		var from, to int
+		if err := rows.Scan(&from, &to); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan clue edge: %w", err)
+		}
+		if clue, ok := clues[from]; ok {
+			clue.Next = append(clue.Next, to)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clue edges: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT name, pathway, members FROM groups WHERE event_id = $1 ORDER BY pathway, name
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	defer rows.Close()
+	hunt.Groups = []NewGroup{}
+	for rows.Next() {
+		var g NewGroup
+		if err := rows.Scan(&g.Name, &g.Pathway, pq.Array(&g.Members)); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		hunt.Groups = append(hunt.Groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating groups: %w", err)
+	}
+
+	return &hunt, nil
+}
+
+// ImportHunt replaces the pathways, clues, hints and rules of an event, and
+// its groups when the hunt has any, all in one transaction. The hunt must
+// already be valid. The game must not have started. QR clues without a code
+// get a signed one, and signed codes are re-signed for this event.
+func (s *EventService) ImportHunt(ctx context.Context, eventID int, hunt *Hunt) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var started bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT game_started FROM game_settings WHERE event_id = $1 FOR UPDATE
+	`, eventID).Scan(&started)
+	if err == sql.ErrNoRows {
+		return ErrNoSettingsRow
+	}
+	if err != nil {
+		return err
+	}
+	if started {
+		return ErrGameAlreadyStarted
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE game_settings
+		SET wrong_scan_penalty_after = $2, wrong_scan_penalty_seconds = $3,
+		    lockout_after = $4, lockout_seconds = $5, max_duration_seconds = $6
+		WHERE event_id = $1
+	`, eventID, hunt.ScanRules.PenaltyAfter, hunt.ScanRules.PenaltySeconds,
+		hunt.ScanRules.LockoutAfter, hunt.ScanRules.LockoutSeconds, hunt.MaxDurationSeconds)
+	if err != nil {
+		return fmt.Errorf("update game settings: %w", err)
+	}
+
+	for _, q := range []string{
+		`DELETE FROM clues WHERE event_id = $1`,
+		`DELETE FROM pathways WHERE event_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, eventID); err != nil {
+			return fmt.Errorf("clear hunt: %w", err)
+		}
+	}
+	if hunt.Groups != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE event_id = $1`, eventID); err != nil {
+			return fmt.Errorf("clear groups: %w", err)
+		}
+		if _, err := insertGroups(ctx, tx, eventID, hunt.Groups); err != nil {
+			return err
+		}
+	}
+
+	secret, err := setQRSecret(ctx, tx, eventID, false)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range hunt.Pathways {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO pathways (event_id, name, color, description, active, total_clues, shuffle_clues, pin_first_clue, pin_last_clue, mode)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, eventID, p.Name, p.Color, p.Description, p.Active, p.TotalClues,
+			p.ClueOrder.Shuffle, p.ClueOrder.PinFirst, p.ClueOrder.PinLast, p.Mode)
+		if err != nil {
+			return fmt.Errorf("insert pathway %s: %w", p.Name, err)
+		}
+
+		ids := make(map[int]int, len(p.Clues)) // index_num -> clue ID
+		for _, clue := range p.Clues {
+			lat, lng, radius, reject := geofenceArgs(clue.Geofence)
+			var id int
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO clues (event_id, pathway, index_num, content, qrcode,
+				                   answer_type, answers, answer_pattern, choices, correct_choice,
+				                   latitude, longitude, radius_meters, geofence_reject,
+				                   checkpoint_label, placement_notes)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+				RETURNING id
+			`, eventID, p.Name, clue.Index, clue.Content, clue.QRCode,
+				clue.Answer.Type, pq.Array(clue.Answer.Answers), clue.Answer.Pattern,
+				pq.Array(clue.Answer.Choices), clue.Answer.CorrectChoice,
+				lat, lng, radius, reject,
+				clue.Checkpoint.Label, clue.Checkpoint.PlacementNotes).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("insert clue %s/%d: %w", p.Name, clue.Index, err)
+			}
+			ids[clue.Index] = id
+
+			for _, h := range clue.Hints {
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO clue_hints (clue_id, position, content, penalty_seconds)
+					VALUES ($1, $2, $3, $4)
+				`, id, h.Position, h.Content, h.PenaltySeconds)
+				if err != nil {
+					return fmt.Errorf("insert hint of clue %s/%d: %w", p.Name, clue.Index, err)
+				}
+			}
+
+			if clue.Answer.Type == models.AnswerTypeQR && clue.QRCode == "" {
+				if err := mintClueQRCode(ctx, tx, eventID, id, secret); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, clue := range p.Clues {
+			for _, to := range clue.Next {
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO clue_edges (from_clue_id, to_clue_id) VALUES ($1, $2)
+				`, ids[clue.Index], ids[to])
+				if err != nil {
+					return fmt.Errorf("insert clue edge %s/%d->%d: %w", p.Name, clue.Index, to, err)
+				}
+			}
+		}
+	}
+
+	// Codes signed for another event would not verify here
+	if _, err := signQRCodes(ctx, tx, eventID, secret, true); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// pathwayTotalCluesExpr computes the length of the pathway aliased as p: the
+// explicit total when one is set, otherwise the number of clues on it.
+const pathwayTotalCluesExpr = `GREATEST(COALESCE(p.total_clues, (SELECT COUNT(*) FROM clues c WHERE c.event_id = p.event_id AND c.pathway = p.name)), 1)`
+
+type PathwayService struct {
+	db *sql.DB
+}
+
+func NewPathwayService(db *sql.DB) *PathwayService {
+	return &PathwayService{db: db}
+}
+
+func (s *PathwayService) GetAllPathways(ctx context.Context, eventID int) ([]*models.Pathway, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.name, p.color, p.description, p.active, p.total_clues, `+pathwayTotalCluesExpr+`,
+		       p.shuffle_clues, p.pin_first_clue, p.pin_last_clue, p.mode
+		FROM pathways p
+		WHERE p.event_id = $1
+		ORDER BY p.id
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pathways: %w", err)
+	}
+	defer rows.Close()
+
+	var pathways []*models.Pathway
+	for rows.Next() {
+		var p models.Pathway
+		if err := rows.Scan(
+			&p.ID, &p.Name, &p.Color, &p.Description, &p.Active, &p.TotalClues, &p.EffectiveTotalClues,
+			&p.ClueOrder.Shuffle, &p.ClueOrder.PinFirst, &p.ClueOrder.PinLast, &p.Mode,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pathway: %w", err)
+		}
+		pathways = append(pathways, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pathways: %w", err)
+	}
+	rows.Close()
+
+	lengths, err := graphPathwayLengths(ctx, s.db, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pathways {
+		if n, ok := lengths[p.Name]; ok {
+			p.EffectiveTotalClues = n
+		}
+	}
+
+	return pathways, nil
+}
+
+// GetActivePathwayNames returns the names of every pathway groups can currently be assigned to.
+func (s *PathwayService) GetActivePathwayNames(ctx context.Context, eventID int) ([]string, error) {
+	pathways, err := s.GetAllPathways(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, p := range pathways {
+		if p.Active {
+			names = append(names, p.Name)
+		}
+	}
+	return names, nil
+}
+
+func (s *PathwayService) GetPathwayByName(ctx context.Context, eventID int, name string) (*models.Pathway, error) {
+	var p models.Pathway
+	err := s.db.QueryRowContext(ctx, `
+		SELECT p.id, p.name, p.color, p.description, p.active, p.total_clues, `+pathwayTotalCluesExpr+`,
+		       p.shuffle_clues, p.pin_first_clue, p.pin_last_clue, p.mode
+		FROM pathways p
+		WHERE p.event_id = $1 AND p.name = $2
+	`, eventID, name).Scan(
+		&p.ID, &p.Name, &p.Color, &p.Description, &p.Active, &p.TotalClues, &p.EffectiveTotalClues,
+		&p.ClueOrder.Shuffle, &p.ClueOrder.PinFirst, &p.ClueOrder.PinLast, &p.Mode,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrPathwayNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pathway %s: %w", name, err)
+	}
+
+	// A graph pathway is as long as its shortest route to the finish
+	if p.Mode == models.PathwayModeGraph {
+		g, err := loadClueGraph(ctx, s.db, eventID, name)
+		if err != nil {
+			return nil, err
+		}
+		p.EffectiveTotalClues = g.length()
+	}
+
+	return &p, nil
+}
+
+// GetTotalClues returns the effective length of a single pathway, defaulting
+// to 1 for unknown pathways.
+func (s *PathwayService) GetTotalClues(ctx context.Context, eventID int, name string) (int, error) {
+	p, err := s.GetPathwayByName(ctx, eventID, name)
+	if err == ErrPathwayNotFound {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return p.EffectiveTotalClues, nil
+}
+
+// SetTotalClues sets the explicit length of a pathway, or of every pathway
+// when name is empty. A nil total reverts to deriving it from the clues.
+func (s *PathwayService) SetTotalClues(ctx context.Context, eventID int, name string, totalClues *int) error {
+	if name == "" {
+		_, err := s.db.ExecContext(ctx, `UPDATE pathways SET total_clues = $2 WHERE event_id = $1`, eventID, totalClues)
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE pathways SET total_clues = $3 WHERE event_id = $1 AND name = $2
+	`, eventID, name, totalClues)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrPathwayNotFound
+	}
+	return nil
+}
+
+// ValidatePathway checks that name refers to a known pathway. When activeOnly
+// is set, deactivated pathways are rejected as well.
+func (s *PathwayService) ValidatePathway(ctx context.Context, eventID int, name string, activeOnly bool) error {
+	p, err := s.GetPathwayByName(ctx, eventID, name)
+	if err == ErrPathwayNotFound {
+		return ErrInvalidPathway
+	}
+	if err != nil {
+		return err
+	}
+	if activeOnly && !p.Active {
+		return ErrInvalidPathway
+	}
+	return nil
+}
+
+func (s *PathwayService) AddPathway(ctx context.Context, eventID int, name, color, description string, active bool, totalClues *int, order models.ClueOrder, mode string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO pathways (event_id, name, color, description, active, total_clues, shuffle_clues, pin_first_clue, pin_last_clue, mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, eventID, name, color, description, active, totalClues, order.Shuffle, order.PinFirst, order.PinLast, mode)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrPathwayExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePathway edits a pathway. Renames are carried over to the groups and
+// clues that reference the old name in the same transaction. Changes to the
+// clue order apply from the next game start.
+func (s *PathwayService) UpdatePathway(ctx context.Context, eventID, id int, name, color, description string, active bool, totalClues *int, order models.ClueOrder, mode string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	err = tx.QueryRowContext(ctx, `
+		SELECT name FROM pathways WHERE id = $1 AND event_id = $2 FOR UPDATE
+	`, id, eventID).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return ErrPathwayNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pathways
+		SET name = $2, color = $3, description = $4, active = $5, total_clues = $6,
+		    shuffle_clues = $7, pin_first_clue = $8, pin_last_clue = $9, mode = $10
+		WHERE id = $1
+	`, id, name, color, description, active, totalClues, order.Shuffle, order.PinFirst, order.PinLast, mode)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrPathwayExists
+		}
+		return fmt.Errorf("failed to update pathway with id %d: %w", id, err)
+	}
+
+	if oldName != name {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE groups SET pathway = $2 WHERE event_id = $3 AND pathway = $1
+		`, oldName, name, eventID); err != nil {
+			return fmt.Errorf("failed to rename pathway on groups: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE clues SET pathway = $2 WHERE event_id = $3 AND pathway = $1
+		`, oldName, name, eventID); err != nil {
+			return fmt.Errorf("failed to rename pathway on clues: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeletePathway removes an unused pathway. Pathways that still have groups or
+// clues must be deactivated instead.
+func (s *PathwayService) DeletePathway(ctx context.Context, eventID, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRowContext(ctx, `
+		SELECT name FROM pathways WHERE id = $1 AND event_id = $2 FOR UPDATE
+	`, id, eventID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return ErrPathwayNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var inUse bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM groups WHERE event_id = $2 AND pathway = $1)
+		    OR EXISTS (SELECT 1 FROM clues WHERE event_id = $2 AND pathway = $1)
+	`, name, eventID).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrPathwayInUse
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pathways WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete pathway with id %d: %w", id, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Preflight severities. Errors leave groups stuck with no clue to play;
+// warnings are likely mistakes that do not stop the game.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// PreflightIssue is a problem with an event's setup found before its game
+// starts.
+type PreflightIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Pathway  string `json:"pathway,omitempty"`
+	ClueID   int    `json:"clue_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// PreflightReport lists every issue found, errors first.
+type PreflightReport struct {
+	Issues []PreflightIssue `json:"issues"`
+}
+
+// HasErrors reports whether the game should not be started as it is.
+func (r *PreflightReport) HasErrors() bool {
+	return slices.ContainsFunc(r.Issues, func(i PreflightIssue) bool { return i.Severity == SeverityError })
+}
+
+// preflightSetup is what Preflight checks: the pathways of an event, its
+// clues ordered by pathway and index_num, the edges between them and how
+// many groups are on each pathway.
+type preflightSetup struct {
+	Pathways []*models.Pathway
+	Clues    []models.Clue
+	Edges    []models.ClueEdge
+	Groups   map[string]int
+}
+
+// Preflight checks the pathways, clues and groups of an event for problems
+// that would leave groups without a clue once the game starts.
+func (s *GameService) Preflight(ctx context.Context, eventID int) (*PreflightReport, error) {
+	var setup preflightSetup
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, active, total_clues, mode FROM pathways WHERE event_id = $1 ORDER BY id
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query pathways: %w", err)
+	}
+	for rows.Next() {
+		var p models.Pathway
+		if err := rows.Scan(&p.Name, &p.Active, &p.TotalClues, &p.Mode); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan pathway: %w", err)
+		}
+		setup.Pathways = append(setup.Pathways, &p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pathways: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT `+clueColumns+` FROM clues WHERE event_id = $1 ORDER BY pathway, index_num
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query clues: %w", err)
+	}
+	for rows.Next() {
+		var clue models.Clue
+		if err := scanClue(rows, &clue); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan clue: %w", err)
+		}
+		setup.Clues = append(setup.Clues, clue)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clues: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT e.from_clue_id, e.to_clue_id
+		FROM clue_edges e
+		JOIN clues f ON f.id = e.from_clue_id
+		WHERE f.event_id = $1
+		ORDER BY e.from_clue_id, e.to_clue_id
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query clue edges: %w", err)
+	}
+	for rows.Next() {
+		var e models.ClueEdge
+		if err := rows.Scan(&e.FromClueID, &e.ToClueID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan clue edge: %w", err)
+		}
+		setup.Edges = append(setup.Edges, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clue edges: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT pathway, COUNT(*) FROM groups WHERE event_id = $1 GROUP BY pathway ORDER BY pathway
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query groups: %w", err)
+	}
+	setup.Groups = map[string]int{}
+	for rows.Next() {
+		var pathway string
+		var n int
+		if err := rows.Scan(&pathway, &n); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan group count: %w", err)
+		}
+		setup.Groups[pathway] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating group counts: %w", err)
+	}
+
+	return preflight(&setup), nil
+}
+
+// preflight finds the problems with an event's setup.
+func preflight(setup *preflightSetup) *PreflightReport {
+	report := &PreflightReport{Issues: []PreflightIssue{}}
+	add := func(severity, code, pathway string, clueID int, format string, args ...any) {
+		report.Issues = append(report.Issues, PreflightIssue{
+			Severity: severity, Code: code, Pathway: pathway, ClueID: clueID,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	clues := map[string][]models.Clue{}
+	for _, clue := range setup.Clues {
+		clues[clue.Pathway] = append(clues[clue.Pathway], clue)
+	}
+	groups := setup.Groups
+
+	known := map[string]bool{}
+	for _, p := range setup.Pathways {
+		known[p.Name] = true
+		onPathway := clues[p.Name]
+
+		if len(onPathway) == 0 {
+			if groups[p.Name] > 0 {
+				add(SeverityError, "no_clues", p.Name, 0, "%d groups are on pathway %s, which has no clues", groups[p.Name], p.Name)
+			} else if p.Active {
+				add(SeverityWarning, "no_clues", p.Name, 0, "Pathway %s has no clues", p.Name)
+			}
+			continue
+		}
+		if !p.Active && groups[p.Name] > 0 {
+			add(SeverityWarning, "inactive_pathway", p.Name, 0, "%d groups are on inactive pathway %s", groups[p.Name], p.Name)
+		}
+
+		if p.Mode == models.PathwayModeGraph {
+			g := newClueGraph(onPathway, setup.Edges)
+			if _, ok := g.dist[g.start]; !ok {
+				add(SeverityError, "no_route", p.Name, g.start, "No finish clue of pathway %s can be reached from its first clue", p.Name)
+			}
+			for _, clue := range onPathway {
+				if _, ok := g.dist[clue.ID]; !ok && clue.ID != g.start {
+					add(SeverityWarning, "dead_end", p.Name, clue.ID, "Clue %d of pathway %s cannot reach a finish clue", clue.Index, p.Name)
+				}
+			}
+			continue
+		}
+
+		// Linear pathways are walked by index_num from 0
+		have := map[int]bool{}
+		for _, clue := range onPathway {
+			have[clue.Index] = true
+		}
+		length := len(onPathway)
+		if p.TotalClues != nil {
+			length = *p.TotalClues
+		}
+		var missing []int
+		for i := 0; i < length; i++ {
+			if !have[i] {
+				missing = append(missing, i)
+			}
+		}
+		if len(missing) > 0 {
+			add(SeverityError, "index_gap", p.Name, 0, "Pathway %s has no clue with index %v", p.Name, missing)
+		}
+		if p.TotalClues != nil && len(onPathway) > *p.TotalClues {
+			add(SeverityWarning, "extra_clues", p.Name, 0, "Pathway %s has %d clues but only %d are played", p.Name, len(onPathway), *p.TotalClues)
+		}
+	}
+
+	for pathway, n := range groups {
+		if !known[pathway] {
+			add(SeverityError, "unknown_pathway", pathway, 0, "%d groups are on pathway %s, which does not exist", n, pathway)
+		}
+	}
+
+	// Duplicate codes would let one QR code solve several clues
+	codes := map[string][]models.Clue{}
+	for _, clue := range setup.Clues {
+		if clue.Answer.Type != models.AnswerTypeQR {
+			continue
+		}
+		if clue.QRCode == "" {
+			add(SeverityError, "missing_qr_code", clue.Pathway, clue.ID, "Clue %d of pathway %s has no QR code", clue.Index, clue.Pathway)
+			continue
+		}
+		codes[clue.QRCode] = append(codes[clue.QRCode], clue)
+	}
+	for code, dupes := range codes {
+		for _, clue := range dupes[1:] {
+			add(SeverityError, "duplicate_qr_code", clue.Pathway, clue.ID,
+				"Clue %d of pathway %s has the same QR code %q as clue %d of pathway %s",
+				clue.Index, clue.Pathway, code, dupes[0].Index, dupes[0].Pathway)
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Severity != b.Severity {
+			return a.Severity == SeverityError
+		}
+		if a.Pathway != b.Pathway {
+			return a.Pathway < b.Pathway
+		}
+		return a.ClueID < b.ClueID
+	})
+	return report
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	linear := func(name string, totalClues ...int) *models.Pathway {
+		p := &models.Pathway{Name: name, Active: true, Mode: models.PathwayModeLinear}
+		if len(totalClues) > 0 {
+			p.TotalClues = &totalClues[0]
+		}
+		return p
+	}
+	graph := func(name string) *models.Pathway {
+		return &models.Pathway{Name: name, Active: true, Mode: models.PathwayModeGraph}
+	}
+	inactive := func(p *models.Pathway) *models.Pathway {
+		p.Active = false
+		return p
+	}
+	// clue makes a QR clue whose code is unique unless one is given
+	clue := func(id int, pathway string, index int, code ...string) models.Clue {
+		c := models.Clue{ID: id, Pathway: pathway, Index: index, QRCode: fmt.Sprintf("code-%d", id),
+			Answer: models.ClueAnswer{Type: models.AnswerTypeQR}}
+		if len(code) > 0 {
+			c.QRCode = code[0]
+		}
+		return c
+	}
+	edges := func(pairs ...[2]int) []models.ClueEdge {
+		var out []models.ClueEdge
+		for _, p := range pairs {
+			out = append(out, models.ClueEdge{FromClueID: p[0], ToClueID: p[1]})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		setup preflightSetup
+		// want lists "severity code pathway clueID" in report order
+		want []string
+	}{
+		{
+			name: "clean",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red"), graph("blue")},
+				Clues: []models.Clue{
+					clue(1, "blue", 0), clue(2, "blue", 1), clue(3, "blue", 2),
+					clue(4, "red", 0), clue(5, "red", 1),
+				},
+				Edges:  edges([2]int{1, 2}, [2]int{1, 3}, [2]int{2, 3}),
+				Groups: map[string]int{"red": 2, "blue": 1},
+			},
+		},
+		{
+			name: "pathway without clues or groups",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red"), inactive(linear("blue"))},
+			},
+			want: []string{"warning no_clues red 0"},
+		},
+		{
+			name: "groups on a pathway without clues",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{inactive(linear("red"))},
+				Groups:   map[string]int{"red": 3},
+			},
+			want: []string{"error no_clues red 0"},
+		},
+		{
+			name: "groups on an inactive pathway",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{inactive(linear("red"))},
+				Clues:    []models.Clue{clue(1, "red", 0)},
+				Groups:   map[string]int{"red": 1},
+			},
+			want: []string{"warning inactive_pathway red 0"},
+		},
+		{
+			name: "index gap",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red")},
+				Clues:    []models.Clue{clue(1, "red", 0), clue(2, "red", 2), clue(3, "red", 3)},
+			},
+			want: []string{"error index_gap red 0"},
+		},
+		{
+			name: "index gap within total clues",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red", 3)},
+				Clues:    []models.Clue{clue(1, "red", 0), clue(2, "red", 1)},
+			},
+			want: []string{"error index_gap red 0"},
+		},
+		{
+			name: "extra clues",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red", 2)},
+				Clues:    []models.Clue{clue(1, "red", 0), clue(2, "red", 1), clue(3, "red", 2)},
+			},
+			want: []string{"warning extra_clues red 0"},
+		},
+		{
+			name: "unknown pathway",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red")},
+				Clues:    []models.Clue{clue(1, "red", 0)},
+				Groups:   map[string]int{"red": 1, "purple": 2},
+			},
+			want: []string{"error unknown_pathway purple 0"},
+		},
+		{
+			name: "missing QR code",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red")},
+				Clues:    []models.Clue{clue(1, "red", 0), clue(2, "red", 1, "")},
+			},
+			want: []string{"error missing_qr_code red 2"},
+		},
+		{
+			name: "typed answers need no QR code",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red")},
+				Clues: []models.Clue{{ID: 1, Pathway: "red", Index: 0,
+					Answer: models.ClueAnswer{Type: models.AnswerTypeText, Answers: []string{"x"}}}},
+			},
+		},
+		{
+			name: "duplicate QR code across pathways",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("red"), linear("blue")},
+				Clues: []models.Clue{
+					clue(1, "blue", 0, "same"), clue(2, "red", 0, "other"), clue(3, "red", 1, "same"), clue(4, "red", 2, "same"),
+				},
+			},
+			want: []string{"error duplicate_qr_code red 3", "error duplicate_qr_code red 4"},
+		},
+		{
+			name: "no route to a finish clue",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{graph("blue")},
+				Clues:    []models.Clue{clue(1, "blue", 0), clue(2, "blue", 1), clue(3, "blue", 2)},
+				Edges:    edges([2]int{1, 2}, [2]int{2, 1}),
+			},
+			want: []string{"error no_route blue 1", "warning dead_end blue 2"},
+		},
+		{
+			name: "dead end",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{graph("blue")},
+				Clues:    []models.Clue{clue(1, "blue", 0), clue(2, "blue", 1), clue(3, "blue", 2), clue(4, "blue", 3)},
+				Edges:    edges([2]int{1, 2}, [2]int{1, 3}, [2]int{3, 4}, [2]int{4, 3}),
+			},
+			want: []string{"warning dead_end blue 3", "warning dead_end blue 4"},
+		},
+		{
+			name: "edges of other pathways are ignored",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{graph("blue"), graph("green")},
+				Clues:    []models.Clue{clue(1, "blue", 0), clue(2, "blue", 1), clue(3, "green", 0)},
+				Edges:    edges([2]int{1, 2}, [2]int{2, 3}, [2]int{3, 1}),
+			},
+		},
+		{
+			name: "errors before warnings",
+			setup: preflightSetup{
+				Pathways: []*models.Pathway{linear("a", 1), linear("b")},
+				Clues:    []models.Clue{clue(1, "a", 0), clue(2, "a", 1), clue(3, "b", 1)},
+			},
+			want: []string{"error index_gap b 0", "warning extra_clues a 0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := preflight(&tt.setup)
+			var got []string
+			for _, issue := range report.Issues {
+				got = append(got, fmt.Sprintf("%s %s %s %d", issue.Severity, issue.Code, issue.Pathway, issue.ClueID))
+				if issue.Message == "" {
+					t.Errorf("issue %s has no message", issue.Code)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("issues = %q, want %q", got, tt.want)
+			}
+			wantErrors := slices.ContainsFunc(tt.want, func(s string) bool { return s[:5] == "error" })
+			if report.HasErrors() != wantErrors {
+				t.Errorf("HasErrors() = %v, want %v", report.HasErrors(), wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Signed QR codes look like ch1.<event id>.<clue id>.<nonce>.<mac>, where
+// mac is a truncated HMAC-SHA256 of everything before it under the event's
+// QR secret.
+const qrTokenPrefix = "ch1."
+
+func isSignedQRCode(code string) bool {
+	return strings.HasPrefix(code, qrTokenPrefix)
+}
+
+func qrTokenMAC(secret []byte, payload string) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil)[:16])
+}
+
+// mintQRToken signs a fresh code for a clue.
+func mintQRToken(secret []byte, eventID, clueID int) (string, error) {
+	nonce := make([]byte, 9)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	payload := fmt.Sprintf("%s%d.%d.%s", qrTokenPrefix, eventID, clueID, base64.RawURLEncoding.EncodeToString(nonce))
+	return payload + "." + qrTokenMAC(secret, payload), nil
+}
+
+// verifyQRToken checks a code's signature and returns the clue it was
+// minted for.
+func verifyQRToken(secret []byte, token string) (clueID int, ok bool) {
+	if len(secret) == 0 || !isSignedQRCode(token) {
+		return 0, false
+	}
+	i := strings.LastIndexByte(token, '.')
+	payload, mac := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(mac), []byte(qrTokenMAC(secret, payload))) {
+		return 0, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(payload, qrTokenPrefix), ".")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	clueID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return clueID, true
+}
+
+// eventQRSecret returns the event's QR secret, or nil before one exists.
+func eventQRSecret(ctx context.Context, q queryRower, eventID int) ([]byte, error) {
+	var secret []byte
+	err := q.QueryRowContext(ctx, `SELECT qr_secret FROM events WHERE id = $1`, eventID).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query QR secret: %w", err)
+	}
+	return secret, nil
+}
+
+// setQRSecret stores a new random QR secret for the event, or only creates
+// one when it has none and replace is false. It returns the secret in use.
+func setQRSecret(ctx context.Context, tx *sql.Tx, eventID int, replace bool) ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate QR secret: %w", err)
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE events SET qr_secret = $2 WHERE id = $1 AND ($3 OR qr_secret IS NULL)
+	`, eventID, secret, replace)
+	if err != nil {
+		return nil, fmt.Errorf("store QR secret: %w", err)
+	}
+	return eventQRSecret(ctx, tx, eventID)
+}
+
+// signQRCodes mints a new code for the event's QR clues: every one of them,
+// or only those that already carry a signed code when signedOnly is set.
+// It returns how many codes were minted.
+func signQRCodes(ctx context.Context, tx *sql.Tx, eventID int, secret []byte, signedOnly bool) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, qrcode FROM clues WHERE event_id = $1 AND answer_type = $2 ORDER BY id FOR UPDATE
+	`, eventID, models.AnswerTypeQR)
+	if err != nil {
+		return 0, fmt.Errorf("query QR clues: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		var code string
+		if err := rows.Scan(&id, &code); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan QR clue: %w", err)
+		}
+		if !signedOnly || isSignedQRCode(code) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating QR clues: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := mintClueQRCode(ctx, tx, eventID, id, secret); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+func mintClueQRCode(ctx context.Context, tx *sql.Tx, eventID, clueID int, secret []byte) error {
+	token, err := mintQRToken(secret, eventID, clueID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE clues SET qrcode = $3 WHERE id = $1 AND event_id = $2`, clueID, eventID, token); err != nil {
+		return fmt.Errorf("store QR code of clue %d: %w", clueID, err)
+	}
+	return nil
+}
+
+// SignQRCodes replaces the code of every QR clue of the event, including
+// hand-written ones, with a signed code.
+func (s *ClueService) SignQRCodes(ctx context.Context, eventID int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	secret, err := setQRSecret(ctx, tx, eventID, false)
+	if err != nil {
+		return 0, err
+	}
+	n, err := signQRCodes(ctx, tx, eventID, secret, false)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// RotateQRSecret replaces the event's QR secret, which invalidates every
+// signed code printed so far, and mints new signed codes for its clues.
+func (s *ClueService) RotateQRSecret(ctx context.Context, eventID int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	secret, err := setQRSecret(ctx, tx, eventID, true)
+	if err != nil {
+		return 0, err
+	}
+	n, err := signQRCodes(ctx, tx, eventID, secret, true)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
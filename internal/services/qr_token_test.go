@@ -0,0 +1,107 @@
+package services
+
+import (
+	"cyberhunt/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestQRTokenRoundTrip(t *testing.T) {
+	secret := []byte("event-secret")
+	token, err := mintQRToken(secret, 3, 42)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+	if !strings.HasPrefix(token, "ch1.3.42.") {
+		t.Errorf("token %q does not carry the event and clue", token)
+	}
+
+	clueID, ok := verifyQRToken(secret, token)
+	if !ok || clueID != 42 {
+		t.Errorf("verifyQRToken(%q) = %d, %v; want 42, true", token, clueID, ok)
+	}
+
+	other, err := mintQRToken(secret, 3, 42)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+	if other == token {
+		t.Errorf("two tokens for the same clue are identical: %q", token)
+	}
+}
+
+func TestQRTokenRejected(t *testing.T) {
+	secret := []byte("event-secret")
+	token, err := mintQRToken(secret, 3, 42)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+	otherEvent, err := mintQRToken([]byte("other-event-secret"), 4, 42)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+
+	// signed builds a token with a valid MAC around an arbitrary payload
+	signed := func(payload string) string {
+		return payload + "." + qrTokenMAC(secret, payload)
+	}
+	i := strings.LastIndexByte(token, '.')
+	payload, mac := token[:i], token[i+1:]
+	flipped := []byte(mac)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	tests := []struct {
+		name   string
+		secret []byte
+		token  string
+	}{
+		{"tampered MAC", secret, payload + "." + string(flipped)},
+		{"truncated MAC", secret, payload + "." + mac[:len(mac)-1]},
+		{"tampered clue ID", secret, strings.Replace(token, ".42.", ".43.", 1)},
+		{"tampered event ID", secret, strings.Replace(token, "ch1.3.", "ch1.4.", 1)},
+		{"other event", secret, otherEvent},
+		{"rotated secret", []byte("rotated-secret"), token},
+		{"no secret", nil, token},
+		{"unsigned code", secret, "clue-42"},
+		{"prefix only", secret, "ch1."},
+		{"missing MAC", secret, payload},
+		{"empty MAC", secret, payload + "."},
+		{"too few parts", secret, signed("ch1.3.42")},
+		{"too many parts", secret, signed("ch1.3.42.nonce.extra")},
+		{"non-numeric clue ID", secret, signed("ch1.3.x.nonce")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if clueID, ok := verifyQRToken(tt.secret, tt.token); ok {
+				t.Errorf("verifyQRToken(%q) accepted the token for clue %d", tt.token, clueID)
+			}
+		})
+	}
+}
+
+func TestSignedQRCodeAnswer(t *testing.T) {
+	secret := []byte("event-secret")
+	token, err := mintQRToken(secret, 3, 42)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+	other, err := mintQRToken(secret, 3, 7)
+	if err != nil {
+		t.Fatalf("mintQRToken: %v", err)
+	}
+	clue := &models.Clue{ID: 42, QRCode: token, Answer: models.ClueAnswer{Type: models.AnswerTypeQR}}
+
+	if !answerAccepted(clue, token, secret) {
+		t.Error("the clue's own code was rejected")
+	}
+	if answerAccepted(clue, other, secret) {
+		t.Error("a code minted for another clue was accepted")
+	}
+	if answerAccepted(clue, token, []byte("rotated-secret")) {
+		t.Error("a code minted before the secret was rotated was accepted")
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"cyberhunt/internal/models"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scan results stored in scan_events.result.
+const (
+	ScanResultCorrect          = "correct"
+	ScanResultWrong            = "wrong"
+	ScanResultAlreadyCompleted = "already_completed"
+	ScanResultLocked           = "locked"
+	ScanResultGameClosed       = "game_closed"
+	ScanResultOutsideGeofence  = "outside_geofence"
+)
+
+// ScanMetadata describes the client that submitted a code.
+type ScanMetadata struct {
+	ClientIP  string
+	UserAgent string
+	// Location is nil when the browser did not report one.
+	Location *ScanLocation
+}
+
+// ScanEventFilter narrows ListScanEvents. Zero values are ignored, except for
+// EventID which is always applied.
+type ScanEventFilter struct {
+	EventID int
+	GroupID int
+	ClueID  int
+	Result  string
+	// OutsideGeofence keeps only submissions made outside a clue's geofence.
+	OutsideGeofence bool
+	Since           *time.Time
+	Until           *time.Time
+	Limit           int
+	Offset          int
+}
+
+type ScanService struct {
+	db *sql.DB
+}
+
+func NewScanService(db *sql.DB) *ScanService {
+	return &ScanService{db: db}
+}
+
+// insertScanEvent records a submission as part of the caller's transaction,
+// with the outcome of the geofence check when the answer solved a clue.
+func insertScanEvent(ctx context.Context, tx *sql.Tx, groupID int, clueID *int, clueIdx int, code, result string, meta ScanMetadata, fence geofenceCheck) error {
+	var lat, lng, accuracy *float64
+	if loc := meta.Location; loc != nil {
+		lat, lng, accuracy = &loc.Latitude, &loc.Longitude, loc.AccuracyMeters
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO scan_events (group_id, clue_id, clue_idx, submitted_code, result, client_ip, user_agent,
+		                         latitude, longitude, accuracy_meters, distance_meters, outside_geofence)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, groupID, clueID, clueIdx, code, result, meta.ClientIP, meta.UserAgent,
+		lat, lng, accuracy, fence.DistanceMeters, fence.Outside)
+	if err != nil {
+		return fmt.Errorf("record scan event: %w", err)
+	}
+	return nil
+}
+
+// ListScanEvents returns one page of scan events, newest first, together with
+// the total number of events matching the filter.
+func (s *ScanService) ListScanEvents(ctx context.Context, filter ScanEventFilter) ([]models.ScanEvent, int, error) {
+	var conds []string
+	var args []any
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	addCond("g.event_id = ?", filter.EventID)
+	if filter.GroupID > 0 {
+		addCond("e.group_id = ?", filter.GroupID)
+	}
+	if filter.ClueID > 0 {
+		addCond("e.clue_id = ?", filter.ClueID)
+	}
+	if filter.Result != "" {
+		addCond("e.result = ?", filter.Result)
+	}
+	if filter.OutsideGeofence {
+		addCond("e.outside_geofence = ?", true)
+	}
+	if filter.Since != nil {
+		addCond("e.created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCond("e.created_at < ?", *filter.Until)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM scan_events e JOIN groups g ON g.id = e.group_id
+		`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count scan events: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.group_id, g.name, e.clue_id, e.clue_idx, g.pathway,
+		       e.submitted_code, e.result, e.client_ip, e.user_agent, e.created_at,
+		       e.latitude, e.longitude, e.accuracy_meters, e.distance_meters, e.outside_geofence
+		FROM scan_events e
+		JOIN groups g ON g.id = e.group_id
+		`+where+`
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch scan events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ScanEvent{}
+	for rows.Next() {
+		var e models.ScanEvent
+		if err := rows.Scan(
+			&e.ID, &e.GroupID, &e.GroupName, &e.ClueID, &e.ClueIdx, &e.Pathway,
+			&e.SubmittedCode, &e.Result, &e.ClientIP, &e.UserAgent, &e.CreatedAt,
+			&e.Latitude, &e.Longitude, &e.AccuracyMeters, &e.DistanceMeters, &e.OutsideGeofence,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating scan events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// GetSolveTimes returns every correct submission made by the event's groups
+// since the given time, grouped by group ID and ordered by solve time. A
+// groupID of 0 returns the solves of every group.
+func (s *ScanService) GetSolveTimes(ctx context.Context, eventID int, since time.Time, groupID int) (map[int][]models.Solve, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.group_id, e.clue_id, e.clue_idx, e.created_at
+		FROM scan_events e
+		JOIN groups g ON g.id = e.group_id
+		WHERE g.event_id = $4 AND e.result = $1 AND e.created_at >= $2 AND ($3 = 0 OR e.group_id = $3)
+		ORDER BY e.group_id, e.created_at, e.id
+	`, ScanResultCorrect, since, groupID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch solve times: %w", err)
+	}
+	defer rows.Close()
+
+	solves := make(map[int][]models.Solve)
+	for rows.Next() {
+		var sv models.Solve
+		if err := rows.Scan(&sv.GroupID, &sv.ClueID, &sv.ClueIdx, &sv.SolvedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan solve time: %w", err)
+		}
+		solves[sv.GroupID] = append(solves[sv.GroupID], sv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating solve times: %w", err)
+	}
+
+	return solves, nil
+}
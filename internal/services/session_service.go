@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// SessionService tracks the logins of groups and admins. A session's ID is
+// the jti claim of the token handed out at login; the token is only valid
+// while the session exists.
+type SessionService struct {
+	db *sql.DB
+}
+
+func NewSessionService(db *sql.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// CreateGroupSession starts a session for a group and returns its ID.
+func (s *SessionService) CreateGroupSession(ctx context.Context, groupID int, expiresAt time.Time) (string, error) {
+	return s.createSession(ctx, &groupID, nil, expiresAt)
+}
+
+// CreateAdminSession starts a session for an admin and returns its ID.
+func (s *SessionService) CreateAdminSession(ctx context.Context, adminID int, expiresAt time.Time) (string, error) {
+	return s.createSession(ctx, nil, &adminID, expiresAt)
+}
+
+func (s *SessionService) createSession(ctx context.Context, groupID, adminID *int, expiresAt time.Time) (string, error) {
+	raw := make([]byte, 18)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate session ID: %w", err)
+	}
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	// Expired sessions are cleared out as new ones are made
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`); err != nil {
+		return "", fmt.Errorf("prune sessions: %w", err)
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, group_id, admin_id, expires_at) VALUES ($1, $2, $3, $4)
+	`, id, groupID, adminID, expiresAt)
+	if err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	return id, nil
+}
+
+// CheckGroupSession returns ErrSessionRevoked unless the session is live and
+// belongs to the group.
+func (s *SessionService) CheckGroupSession(ctx context.Context, id string, groupID int) error {
+	return s.checkSession(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND group_id = $2 AND expires_at > NOW())
+	`, id, groupID)
+}
+
+// CheckAdminSession returns ErrSessionRevoked unless the session is live and
+// belongs to the admin.
+func (s *SessionService) CheckAdminSession(ctx context.Context, id string, adminID int) error {
+	return s.checkSession(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND admin_id = $2 AND expires_at > NOW())
+	`, id, adminID)
+}
+
+func (s *SessionService) checkSession(ctx context.Context, query, id string, ownerID int) error {
+	var ok bool
+	if err := s.db.QueryRowContext(ctx, query, id, ownerID).Scan(&ok); err != nil {
+		return fmt.Errorf("check session: %w", err)
+	}
+	if !ok {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// RevokeSession ends a single session, e.g. on logout. Unknown sessions are
+// ignored.
+func (s *SessionService) RevokeSession(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeGroupSessions logs a group of the event out everywhere and returns
+// how many sessions were ended. It returns sql.ErrNoRows if the group does
+// not exist.
+func (s *SessionService) RevokeGroupSessions(ctx context.Context, eventID, groupID int) (int, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1 AND event_id = $2)
+	`, groupID, eventID).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("check group %d: %w", groupID, err)
+	}
+	if !exists {
+		return 0, sql.ErrNoRows
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE group_id = $1`, groupID)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions of group %d: %w", groupID, err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
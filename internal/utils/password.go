@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when no account matches, so failed lookups
+// take as long as failed password checks.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("cyberhunt-dummy-password"), bcrypt.DefaultCost)
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsPasswordHash reports whether stored is a bcrypt hash rather than a
+// legacy plaintext password.
+func IsPasswordHash(stored string) bool {
+	return len(stored) == 60 &&
+		(strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$"))
+}
+
+// CheckPassword verifies password against a stored bcrypt hash or, for rows
+// created before hashing was introduced, a plaintext value. needsRehash is
+// set when the password matched a legacy plaintext value.
+func CheckPassword(stored, password string) (ok bool, needsRehash bool) {
+	if IsPasswordHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil, false
+	}
+	match := subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	return match, match
+}
+
+// RejectPassword burns the same time as a real hash comparison. Call it when
+// the account does not exist.
+func RejectPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// passwordChars are the characters of generated passwords. Its length divides
+// 252, so bytes below that map onto it without bias.
+const passwordChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// GenerateSecurePassword returns a random password from crypto/rand. Use it
+// for every credential handed out.
+func GenerateSecurePassword(length int) string {
+	limit := byte(256 - 256%len(passwordChars))
+	result := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(result) < length {
+		// crypto/rand.Read never fails
+		rand.Read(buf)
+		for _, b := range buf {
+			if b < limit && len(result) < length {
+				result = append(result, passwordChars[int(b)%len(passwordChars)])
+			}
+		}
+	}
+	return string(result)
+}
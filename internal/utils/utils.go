@@ -1,12 +0,0 @@
-package utils
-
-import "math/rand"
-
-func GenerateRandomPassword(length int) string {
-	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = chars[rand.Intn(len(chars))]
-	}
-	return string(result)
-}